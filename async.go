@@ -0,0 +1,151 @@
+package log
+
+import (
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy decides what to do when the queue of an AsyncWriter is full.
+type OverflowPolicy int
+
+// Overflow policies.
+const (
+	// OverflowBlock blocks the writer until there is room in the queue.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest discards the bytes being written.
+	OverflowDropNewest
+	// OverflowDropOldest discards the oldest bytes in the queue to make room.
+	OverflowDropOldest
+)
+
+// AsyncWriter is an io.Writer which hands bytes to a background goroutine writing them to the underlying writer,
+// through a bounded queue whose overflow is handled according to an OverflowPolicy.
+//
+// Errors of the underlying writer are reported by Flush and Close.
+type AsyncWriter struct {
+	w       io.Writer
+	policy  OverflowPolicy
+	queue   chan []byte
+	stopped chan struct{}
+	dropped uint64
+
+	// mu guards closed and the queue from being closed while sending.
+	mu     sync.RWMutex
+	closed bool
+
+	// progress tracks the number of queued and processed writes for Flush.
+	progress  sync.Mutex
+	processed *sync.Cond
+	queued    uint64
+	done      uint64
+	err       error
+}
+
+// NewAsyncWriter creates an AsyncWriter writing to w with a queue of size writes.
+func NewAsyncWriter(w io.Writer, size int, policy OverflowPolicy) *AsyncWriter {
+	aw := &AsyncWriter{
+		w:       w,
+		policy:  policy,
+		queue:   make(chan []byte, size),
+		stopped: make(chan struct{}),
+	}
+	aw.processed = sync.NewCond(&aw.progress)
+	go aw.run()
+	return aw
+}
+
+// Write copies p into the queue, it never returns errors of the underlying writer.
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	b := make([]byte, len(p))
+	copy(b, p)
+
+	w.progress.Lock()
+	w.queued++
+	w.progress.Unlock()
+
+	switch w.policy {
+	case OverflowDropNewest:
+		select {
+		case w.queue <- b:
+		default:
+			w.drop()
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case w.queue <- b:
+				return len(p), nil
+			default:
+			}
+			select {
+			case <-w.queue:
+				w.drop()
+			default:
+			}
+		}
+	default:
+		w.queue <- b
+	}
+	return len(p), nil
+}
+
+// Dropped returns the number of writes discarded due to overflow.
+func (w *AsyncWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&w.dropped)
+}
+
+// Flush waits until all the bytes written before are written to the underlying writer or dropped,
+// the first error of the underlying writer is returned.
+func (w *AsyncWriter) Flush() error {
+	w.progress.Lock()
+	defer w.progress.Unlock()
+
+	target := w.queued
+	for w.done < target {
+		w.processed.Wait()
+	}
+	return w.err
+}
+
+// Close flushes and stops the background goroutine, the underlying writer is not closed.
+func (w *AsyncWriter) Close() error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.mu.Unlock()
+
+	<-w.stopped
+	return w.Flush()
+}
+
+func (w *AsyncWriter) run() {
+	defer close(w.stopped)
+	for b := range w.queue {
+		_, err := w.w.Write(b)
+		w.progress.Lock()
+		if err != nil && w.err == nil {
+			w.err = err
+		}
+		w.done++
+		w.processed.Broadcast()
+		w.progress.Unlock()
+	}
+}
+
+func (w *AsyncWriter) drop() {
+	atomic.AddUint64(&w.dropped, 1)
+	w.progress.Lock()
+	w.done++
+	w.processed.Broadcast()
+	w.progress.Unlock()
+}
@@ -0,0 +1,102 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+// gatedWriter blocks writing until the gate is opened.
+type gatedWriter struct {
+	gate chan struct{}
+	mu   sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	<-w.gate
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *gatedWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestAsyncWriterOverflow(t *testing.T) {
+	cases := []struct {
+		policy  OverflowPolicy
+		exp     string
+		dropped uint64
+	}{
+		{OverflowDropNewest, "012", 2},
+		{OverflowDropOldest, "034", 2},
+	}
+	for _, c := range cases {
+		w := &gatedWriter{gate: make(chan struct{})}
+		aw := NewAsyncWriter(w, 2, c.policy)
+		for i := 0; i < 5; i++ {
+			if _, err := fmt.Fprint(aw, i); err != nil {
+				t.Fatal(err)
+			}
+			if i == 0 {
+				// Wait for the first write to be taken by the background goroutine.
+				for len(aw.queue) != 0 {
+					runtime.Gosched()
+				}
+			}
+		}
+		close(w.gate)
+		if err := aw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if w.String() != c.exp {
+			t.Errorf("Policy %d: expected '%s', got '%s'", c.policy, c.exp, w.String())
+		}
+		if aw.Dropped() != c.dropped {
+			t.Errorf("Policy %d: expected %d dropped, got %d", c.policy, c.dropped, aw.Dropped())
+		}
+	}
+}
+
+func TestAsyncWriterBlock(t *testing.T) {
+	w := &gatedWriter{gate: make(chan struct{})}
+	close(w.gate)
+	aw := NewAsyncWriter(w, 1, OverflowBlock)
+	for i := 0; i < 100; i++ {
+		if _, err := fmt.Fprintln(aw, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := aw.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if lines := bytes.Count([]byte(w.String()), []byte("\n")); lines != 100 {
+		t.Errorf("Expected 100 lines flushed, got %d", lines)
+	}
+	if err := aw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := aw.Write([]byte("closed")); err == nil {
+		t.Errorf("Error is expected while writing to a closed AsyncWriter")
+	}
+}
+
+func TestLeveledLoggerAsync(t *testing.T) {
+	w := &gatedWriter{gate: make(chan struct{})}
+	close(w.gate)
+	l := NewLeveledLoggerWithColor(w, 0, false)
+	l.SetAsync(16, OverflowBlock)
+	l.With("k", "v").Info("async")
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if w.String() != "I async k=v\n" {
+		t.Errorf("Expected 'I async k=v', got '%s'", w.String())
+	}
+}
@@ -0,0 +1,76 @@
+package log
+
+import (
+	"path"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// funcName returns the name of function without the path of its package, e.g. "log.(*LeveledLogger).Info".
+func funcName(function string) string {
+	return function[lastSlash(function)+1:]
+}
+
+// packagePath returns the import path of the package of function, e.g. "github.com/tevino/log".
+func packagePath(function string) string {
+	i := lastSlash(function) + 1
+	if j := strings.IndexByte(function[i:], '.'); j >= 0 {
+		return function[:i+j]
+	}
+	return function
+}
+
+// lastSlash returns the index of the last slash in function, ignoring those in type parameters.
+func lastSlash(function string) int {
+	if i := strings.IndexByte(function, '['); i >= 0 {
+		function = function[:i]
+	}
+	return strings.LastIndexByte(function, '/')
+}
+
+// buildInfo is the module information of the binary, which is loaded once.
+var buildInfo = sync.OnceValues(func() (mainPath string, modules []string) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "", nil
+	}
+	modules = append(modules, info.Main.Path)
+	for _, dep := range info.Deps {
+		modules = append(modules, dep.Path)
+	}
+	// Longer paths go first so that nested modules are matched before their parents.
+	sort.Slice(modules, func(i, j int) bool {
+		return len(modules[i]) > len(modules[j])
+	})
+	return info.Path, modules
+})
+
+// moduleFile returns the file name of frame relative to the root of the module containing it, e.g. "c/d.go".
+// The module is looked up by the package of the function in the build information,
+// the import path of the package is used in place of the module root if it's not found, e.g. "a/b/c/d.go".
+func moduleFile(frame runtime.Frame) string {
+	name := path.Base(frame.File)
+	if frame.Function == "" {
+		return name
+	}
+	mainPath, modules := buildInfo()
+	pkg := packagePath(frame.Function)
+	if pkg == "main" && mainPath != "" {
+		pkg = mainPath
+	}
+	for _, mod := range modules {
+		if mod == "" {
+			continue
+		}
+		if pkg == mod {
+			return name
+		}
+		if strings.HasPrefix(pkg, mod) && pkg[len(mod)] == '/' {
+			return pkg[len(mod)+1:] + "/" + name
+		}
+	}
+	return pkg + "/" + name
+}
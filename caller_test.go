@@ -0,0 +1,60 @@
+package log
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+)
+
+func TestFunctionAndModuleFile(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLeveledLoggerWithColor(&buf, Lmodulefile|Lfunction, false)
+	l.Info("msg")
+	if exp := "I caller_test.go:12 log.TestFunctionAndModuleFile: msg\n"; buf.String() != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, buf.String())
+	}
+
+	buf.Reset()
+	l.SetCallerOffset(1)
+	wrapper := func() { l.Info("wrapped") }
+	wrapper()
+	if exp := "I caller_test.go:20 log.TestFunctionAndModuleFile: wrapped\n"; buf.String() != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, buf.String())
+	}
+
+	buf.Reset()
+	j := NewJSONLogger(&buf, Lfunction)
+	j.Info("msg")
+	if exp := `{"level":"INFO","func":"log.TestFunctionAndModuleFile","msg":"msg"}` + "\n"; buf.String() != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, buf.String())
+	}
+}
+
+func TestModuleFile(t *testing.T) {
+	for _, c := range []struct {
+		frame runtime.Frame
+		exp   string
+	}{
+		{runtime.Frame{Function: "github.com/tevino/log.(*LeveledLogger).Info", File: "/src/log/leveled_logger.go"}, "leveled_logger.go"},
+		{runtime.Frame{Function: "github.com/tevino/log/logtest.New", File: "/src/log/logtest/logtest.go"}, "logtest/logtest.go"},
+		{runtime.Frame{Function: "example.com/a/b.(*T[example.com/c.D]).Run", File: "/src/b/t.go"}, "example.com/a/b/t.go"},
+		{runtime.Frame{File: "/src/b/t.go"}, "t.go"},
+	} {
+		if got := moduleFile(c.frame); got != c.exp {
+			t.Errorf("Expected '%s' for %s, got '%s'", c.exp, c.frame.Function, got)
+		}
+	}
+}
+
+func TestFuncName(t *testing.T) {
+	for function, exp := range map[string]string{
+		"main.main": "main.main",
+		"github.com/tevino/log.(*LeveledLogger).Info": "log.(*LeveledLogger).Info",
+		"example.com/a/b.(*T[example.com/c.D]).Run":   "b.(*T[example.com/c.D]).Run",
+		"example.com/a/b.F.func1":                     "b.F.func1",
+	} {
+		if got := funcName(function); got != exp {
+			t.Errorf("Expected '%s' for %s, got '%s'", exp, function, got)
+		}
+	}
+}
@@ -4,7 +4,6 @@
 package log
 
 import (
-	"fmt"
 	"io"
 	"os"
 	"syscall"
@@ -23,10 +22,6 @@ const (
 	ColorRST = "\x1b[0;m"
 )
 
-func paint(str string, color string) string {
-	return fmt.Sprintf("%s%s%s", color, str, ColorRST)
-}
-
 // IsColoredTerminal returns true if the given writer supports colored output.
 func IsColoredTerminal(w io.Writer) bool {
 	var fd int
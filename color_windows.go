@@ -3,10 +3,7 @@
 
 package log
 
-import (
-	"fmt"
-	"io"
-)
+import "io"
 
 // Dummy colors.
 const (
@@ -19,10 +16,6 @@ const (
 	ColorRST = ""
 )
 
-func paint(str string, color string) string {
-	return fmt.Sprintf("%s%s%s", color, str, ColorRST)
-}
-
 // IsColoredTerminal returns true.
 func IsColoredTerminal(_ io.Writer) bool {
 	// TODO: color support for Windows.
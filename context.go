@@ -0,0 +1,66 @@
+package log
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// ContextExtractor extracts fields from a context.Context, e.g. request ID or trace ID.
+type ContextExtractor func(ctx context.Context) []Field
+
+var (
+	extractorsMu sync.Mutex
+	extractors   atomic.Value // []ContextExtractor
+)
+
+// RegisterContextExtractor registers an extractor whose fields are added to logs of *Ctx methods.
+// Fields are added in the order extractors are registered.
+func RegisterContextExtractor(extractor ContextExtractor) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+
+	old, _ := extractors.Load().([]ContextExtractor)
+	registered := make([]ContextExtractor, 0, len(old)+1)
+	registered = append(registered, old...)
+	extractors.Store(append(registered, extractor))
+}
+
+// resetContextExtractors unregisters all the extractors, it's used by tests.
+func resetContextExtractors() {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+
+	extractors.Store([]ContextExtractor(nil))
+}
+
+// fieldsFromContext returns the fields extracted from ctx by all the registered extractors.
+func fieldsFromContext(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+	registered, _ := extractors.Load().([]ContextExtractor)
+	var fields []Field
+	for _, extract := range registered {
+		fields = append(fields, extract(ctx)...)
+	}
+	return fields
+}
+
+type loggerKey struct{}
+
+// NewContext returns a copy of ctx carrying logger.
+func NewContext(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the Logger carried by ctx,
+// or the default logger with caller offset reset as in With if there is none.
+func FromContext(ctx context.Context) Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerKey{}).(Logger); ok {
+			return logger
+		}
+	}
+	return With()
+}
@@ -0,0 +1,45 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+type requestIDKey struct{}
+
+func TestContextExtractor(t *testing.T) {
+	t.Cleanup(resetContextExtractors)
+	RegisterContextExtractor(func(ctx context.Context) []Field {
+		if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+			return []Field{F("request_id", id)}
+		}
+		return nil
+	})
+
+	var buf bytes.Buffer
+	l := NewLeveledLoggerWithColor(&buf, Lshortfile, false)
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "abc")
+	l.InfoCtx(ctx, "served")
+	l.WarnfCtx(context.Background(), "served %d", 2)
+
+	exp := "I context_test.go:23: served request_id=abc\n" +
+		"W context_test.go:24: served 2\n"
+	if buf.String() != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, buf.String())
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLeveledLoggerWithColor(&buf, 0, false)
+	ctx := NewContext(context.Background(), l)
+	FromContext(ctx).Info("from context")
+	if buf.String() != "I from context\n" {
+		t.Errorf("Expected 'I from context', got '%s'", buf.String())
+	}
+
+	if FromContext(context.Background()) == nil {
+		t.Errorf("Default logger is expected if there is no logger in context")
+	}
+}
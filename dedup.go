@@ -0,0 +1,146 @@
+package log
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// deduper collapses identical consecutive logs, it's shared between a LeveledLogger and its children.
+type deduper struct {
+	window int64 // time.Duration, accessed atomically to avoid locking while disabled
+
+	mu    sync.Mutex
+	last  *dedupEntry
+	timer *time.Timer
+}
+
+// dedupEntry is the last log written and the number of its repetitions suppressed since.
+type dedupEntry struct {
+	logger   *LeveledLogger
+	level    Level
+	caller   string
+	line     int
+	name     string
+	message  string
+	fields   string // bound fields rendered by appendFields
+	start    time.Time
+	repeated int
+}
+
+// SetDedup collapses identical consecutive logs within window, which have the same level, caller, message and fields.
+// The first of them is outputted and the rest are suppressed until a different log comes, the window is over,
+// or l is closed, which is also done by Fatal* methods. Then a log of the same level and caller is outputted, e.g.
+//
+//	E 2009/01/23 01:23:23 worker.go:42: last message repeated 27 times
+//
+// Hooks are fired for every log regardless. Zero window disables deduplication.
+// Windows are measured by the clock set by SetClock.
+// It's shared between l and its children.
+func (l *LeveledLogger) SetDedup(window time.Duration) {
+	d := l.deduper
+	d.mu.Lock()
+	atomic.StoreInt64(&d.window, int64(window))
+	summary := d.reset()
+	d.mu.Unlock()
+
+	summary.write()
+}
+
+// suppress reports whether e repeats the last log, a summary is written before e otherwise if needed.
+func (d *deduper) suppress(l *LeveledLogger, e *Entry) bool {
+	if atomic.LoadInt64(&d.window) <= 0 {
+		return false
+	}
+	d.mu.Lock()
+	window := time.Duration(atomic.LoadInt64(&d.window))
+	if window <= 0 {
+		d.mu.Unlock()
+		return false
+	}
+	var fields string
+	if len(e.Fields) > 0 {
+		fields = string(appendFields(nil, e.Fields))
+	}
+	now := l.now()
+	if last := d.last; last != nil && last.matches(e, fields) && now.Sub(last.start) < window {
+		last.repeated++
+		if d.timer == nil {
+			d.timer = time.AfterFunc(window-now.Sub(last.start), d.expire)
+		}
+		d.mu.Unlock()
+		return true
+	}
+	summary := d.reset()
+	d.last = &dedupEntry{
+		logger:  l,
+		level:   e.Level,
+		caller:  e.Caller.File,
+		line:    e.Caller.Line,
+		name:    e.Name,
+		message: e.Message,
+		fields:  fields,
+		start:   now,
+	}
+	d.mu.Unlock()
+
+	summary.write()
+	return false
+}
+
+// expire ends the window of the last log.
+func (d *deduper) expire() {
+	d.mu.Lock()
+	d.timer = nil
+	summary := d.reset()
+	d.mu.Unlock()
+
+	summary.write()
+}
+
+// flush ends the window of the last log and writes the summary immediately.
+func (d *deduper) flush() {
+	d.mu.Lock()
+	summary := d.reset()
+	d.mu.Unlock()
+
+	summary.write()
+}
+
+// reset forgets the last log and stops the timer, the summary of the last log is returned.
+// Summaries are written without locking since hooks may log.
+func (d *deduper) reset() *dedupEntry {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	last := d.last
+	d.last = nil
+	return last
+}
+
+func (last *dedupEntry) matches(e *Entry, fields string) bool {
+	return last.level == e.Level && last.line == e.Caller.Line && last.caller == e.Caller.File &&
+		last.name == e.Name && last.message == e.Message && last.fields == fields
+}
+
+// write writes the summary of the repetitions if there is any.
+func (last *dedupEntry) write() {
+	if last == nil || last.repeated == 0 {
+		return
+	}
+	l := last.logger
+	e := entryPool.Get().(*Entry)
+	e.Level = last.level
+	e.Message = fmt.Sprintf("last message repeated %d times", last.repeated)
+	e.Name = last.name
+	e.Caller.File = last.caller
+	e.Caller.Line = last.line
+	if hasTime(l.flag) {
+		e.Time = l.now()
+	}
+	l.handler.handle(e)
+	*e = Entry{}
+	entryPool.Put(e)
+}
@@ -0,0 +1,82 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestDedup(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLeveledLoggerWithColor(&buf, Lshortfile, false)
+	l.SetDedup(time.Hour)
+
+	for i := 0; i < 5; i++ {
+		l.Error("retry failed")
+	}
+	l.Error("retry failed")
+	l.With("k", "v").Error("retry failed")
+	l.Warn("retry failed")
+	exp := "E dedup_test.go:15: retry failed\n" +
+		"E dedup_test.go:15: last message repeated 4 times\n" +
+		"E dedup_test.go:17: retry failed\n" +
+		"E dedup_test.go:18: retry failed k=v\n" +
+		"W dedup_test.go:19: retry failed\n"
+	if buf.String() != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, buf.String())
+	}
+
+	buf.Reset()
+	for i := 0; i < 2; i++ {
+		l.Warn("closing")
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	exp = "W dedup_test.go:31: closing\n" +
+		"W dedup_test.go:31: last message repeated 1 times\n"
+	if buf.String() != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, buf.String())
+	}
+}
+
+func TestDedupWindow(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLeveledLoggerWithColor(&buf, 0, false)
+	now := time.Unix(1232670203, 0)
+	l.SetClock(func() time.Time { return now })
+	l.SetDedup(time.Hour)
+
+	for i := 0; i < 3; i++ {
+		l.Info("polling")
+	}
+	now = now.Add(time.Hour)
+	l.Info("polling")
+	exp := "I polling\nI last message repeated 2 times\nI polling\n"
+	if buf.String() != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, buf.String())
+	}
+
+	l.SetDedup(0)
+	l.Info("polling")
+	if exp += "I polling\n"; buf.String() != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, buf.String())
+	}
+}
+
+func TestDedupFields(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLeveledLoggerWithColor(&buf, Lshortfile, false)
+	l.SetDedup(time.Hour)
+
+	for _, user := range []int{1, 2, 2, 3} {
+		l.With("user", user).Error("retry failed")
+	}
+	exp := "E dedup_test.go:73: retry failed user=1\n" +
+		"E dedup_test.go:73: retry failed user=2\n" +
+		"E dedup_test.go:73: last message repeated 1 times\n" +
+		"E dedup_test.go:73: retry failed user=3\n"
+	if buf.String() != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, buf.String())
+	}
+}
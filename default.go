@@ -1,6 +1,10 @@
 package log
 
-import "os"
+import (
+	"context"
+	"os"
+	"time"
+)
 
 var defaultLogger Logger
 
@@ -14,10 +18,28 @@ func DefaultLogger() Logger {
 }
 
 // SetDefaultLogger sets the default logger with CallerOffset set.
+// Named loggers got by GetLogger afterwards derive from the new default logger.
 // NOTE: this function is supposed to be called while DefaultLogger is not in use, it's not goroutine safe for performance.
 func SetDefaultLogger(logger Logger) {
 	logger.SetCallerOffset(1)
 	defaultLogger = logger
+	resetNamedLoggers()
+}
+
+// With calls the same method on the default logger.
+// The caller offset of the returned logger is reset to 0 since it is called directly.
+func With(keyvals ...interface{}) Logger {
+	logger := defaultLogger.With(keyvals...)
+	logger.SetCallerOffset(0)
+	return logger
+}
+
+// WithFields calls the same method on the default logger.
+// The caller offset of the returned logger is reset to 0 since it is called directly.
+func WithFields(fields ...Field) Logger {
+	logger := defaultLogger.WithFields(fields...)
+	logger.SetCallerOffset(0)
+	return logger
 }
 
 // Print calls the same method on the default logger.
@@ -67,12 +89,62 @@ func Warnf(f string, a ...interface{}) {
 
 // Error calls the same method on the default logger.
 func Error(a ...interface{}) {
-	defaultLogger.Warn(a...)
+	defaultLogger.Error(a...)
 }
 
 // Errorf calls the same method on the default logger.
 func Errorf(f string, a ...interface{}) {
-	defaultLogger.Warnf(f, a...)
+	defaultLogger.Errorf(f, a...)
+}
+
+// DebugCtx calls the same method on the default logger.
+func DebugCtx(ctx context.Context, a ...interface{}) {
+	defaultLogger.DebugCtx(ctx, a...)
+}
+
+// DebugfCtx calls the same method on the default logger.
+func DebugfCtx(ctx context.Context, f string, a ...interface{}) {
+	defaultLogger.DebugfCtx(ctx, f, a...)
+}
+
+// InfoCtx calls the same method on the default logger.
+func InfoCtx(ctx context.Context, a ...interface{}) {
+	defaultLogger.InfoCtx(ctx, a...)
+}
+
+// InfofCtx calls the same method on the default logger.
+func InfofCtx(ctx context.Context, f string, a ...interface{}) {
+	defaultLogger.InfofCtx(ctx, f, a...)
+}
+
+// WarnCtx calls the same method on the default logger.
+func WarnCtx(ctx context.Context, a ...interface{}) {
+	defaultLogger.WarnCtx(ctx, a...)
+}
+
+// WarnfCtx calls the same method on the default logger.
+func WarnfCtx(ctx context.Context, f string, a ...interface{}) {
+	defaultLogger.WarnfCtx(ctx, f, a...)
+}
+
+// ErrorCtx calls the same method on the default logger.
+func ErrorCtx(ctx context.Context, a ...interface{}) {
+	defaultLogger.ErrorCtx(ctx, a...)
+}
+
+// ErrorfCtx calls the same method on the default logger.
+func ErrorfCtx(ctx context.Context, f string, a ...interface{}) {
+	defaultLogger.ErrorfCtx(ctx, f, a...)
+}
+
+// Panic calls the same method on the default logger.
+func Panic(a ...interface{}) {
+	defaultLogger.Panic(a...)
+}
+
+// Panicf calls the same method on the default logger.
+func Panicf(f string, a ...interface{}) {
+	defaultLogger.Panicf(f, a...)
 }
 
 // Fatal calls the same method on the default logger.
@@ -105,6 +177,31 @@ func SetOutputLevel(l Level) {
 	defaultLogger.SetOutputLevel(l)
 }
 
+// AddHook calls the same method on the default logger.
+func AddHook(hook Hook) {
+	defaultLogger.AddHook(hook)
+}
+
+// SetExitFunc calls the same method on the default logger.
+func SetExitFunc(exitFunc func(code int)) {
+	defaultLogger.SetExitFunc(exitFunc)
+}
+
+// SetExitCode calls the same method on the default logger.
+func SetExitCode(code int) {
+	defaultLogger.SetExitCode(code)
+}
+
+// SetExitTimeout calls the same method on the default logger.
+func SetExitTimeout(timeout time.Duration) {
+	defaultLogger.SetExitTimeout(timeout)
+}
+
+// RegisterExitHandler calls the same method on the default logger.
+func RegisterExitHandler(handler func()) {
+	defaultLogger.RegisterExitHandler(handler)
+}
+
 // SetCallerOffset calls the same method on the default logger.
 func SetCallerOffset(offset int) {
 	defaultLogger.SetCallerOffset(offset)
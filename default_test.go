@@ -0,0 +1,25 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDefaultLoggerLevels(t *testing.T) {
+	defer SetDefaultLogger(DefaultLogger())
+	var buf bytes.Buffer
+	SetDefaultLogger(NewLeveledLoggerWithColor(&buf, 0, false))
+
+	Debug("d")
+	Debugf("%s", "d")
+	Info("i")
+	Infof("%s", "i")
+	Warn("w")
+	Warnf("%s", "w")
+	Error("e")
+	Errorf("%s", "e")
+	exp := "D d\nD d\nI i\nI i\nW w\nW w\nE e\nE e\n"
+	if buf.String() != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, buf.String())
+	}
+}
@@ -0,0 +1,165 @@
+package log
+
+import (
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Entry represents a single log entry.
+type Entry struct {
+	Time    time.Time
+	Level   Level
+	Caller  runtime.Frame
+	Message string
+	Fields  []Field
+	// Name is the name of the logger, it's empty unless the logger is got by GetLogger.
+	Name string
+	// Stack is the stack trace captured if Lstack is set, lines are separated by '\n' without the trailing one.
+	Stack string
+}
+
+var entryPool = sync.Pool{
+	New: func() interface{} {
+		return new(Entry)
+	},
+}
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, 0, 256)
+		return &buf
+	},
+}
+
+// Time layouts of formatters in addition to those of package time, which render times as Unix epochs.
+const (
+	TimeUnix      = "unix"
+	TimeUnixMilli = "unixmilli"
+	TimeUnixMicro = "unixmicro"
+	TimeUnixNano  = "unixnano"
+)
+
+// isUnixTime reports whether layout renders times as Unix epochs.
+func isUnixTime(layout string) bool {
+	switch layout {
+	case TimeUnix, TimeUnixMilli, TimeUnixMicro, TimeUnixNano:
+		return true
+	}
+	return false
+}
+
+// hasTime reports whether the flag requires time to be logged.
+func hasTime(flag int) bool {
+	return flag&(Ldate|Ltime|Lmicroseconds) != 0
+}
+
+// hasCaller reports whether the flag requires the caller to be collected.
+func hasCaller(flag int) bool {
+	return flag&(Lshortfile|Llongfile|Lmodulefile|Lfunction) != 0
+}
+
+// hasFile reports whether the flag requires file name and line number to be logged.
+func hasFile(flag int) bool {
+	return flag&(Lshortfile|Llongfile|Lmodulefile) != 0
+}
+
+// hasStack reports whether the flag requires stack traces to be logged.
+func hasStack(flag int) bool {
+	return flag&Lstack != 0
+}
+
+// appendTime appends t to buf in layout, which is one of the Time* layouts or a layout of package time.
+// If layout is empty, the same layout as the standard package log is used according to flag, without the trailing space.
+func appendTime(buf []byte, t time.Time, flag int, layout string) []byte {
+	if flag&LUTC != 0 {
+		t = t.UTC()
+	}
+	switch layout {
+	case "":
+	case TimeUnix:
+		return strconv.AppendInt(buf, t.Unix(), 10)
+	case TimeUnixMilli:
+		return strconv.AppendInt(buf, t.UnixMilli(), 10)
+	case TimeUnixMicro:
+		return strconv.AppendInt(buf, t.UnixMicro(), 10)
+	case TimeUnixNano:
+		return strconv.AppendInt(buf, t.UnixNano(), 10)
+	default:
+		return t.AppendFormat(buf, layout)
+	}
+	if flag&Ldate != 0 {
+		year, month, day := t.Date()
+		buf = itoa(buf, year, 4)
+		buf = append(buf, '/')
+		buf = itoa(buf, int(month), 2)
+		buf = append(buf, '/')
+		buf = itoa(buf, day, 2)
+	}
+	if flag&(Ltime|Lmicroseconds) != 0 {
+		if flag&Ldate != 0 {
+			buf = append(buf, ' ')
+		}
+		hour, min, sec := t.Clock()
+		buf = itoa(buf, hour, 2)
+		buf = append(buf, ':')
+		buf = itoa(buf, min, 2)
+		buf = append(buf, ':')
+		buf = itoa(buf, sec, 2)
+		if flag&Lmicroseconds != 0 {
+			buf = append(buf, '.')
+			buf = itoa(buf, t.Nanosecond()/1e3, 6)
+		}
+	}
+	return buf
+}
+
+// appendCaller appends file name and line number of frame to buf in the form of "file:line".
+// The file name is relative to the module root if Lmodulefile is set,
+// otherwise only the final element of it is used if Lshortfile is set.
+func appendCaller(buf []byte, frame runtime.Frame, flag int) []byte {
+	file := frame.File
+	if flag&Lmodulefile != 0 {
+		file = moduleFile(frame)
+	} else if flag&Lshortfile != 0 {
+		for i := len(file) - 1; i > 0; i-- {
+			if file[i] == '/' {
+				file = file[i+1:]
+				break
+			}
+		}
+	}
+	buf = append(buf, file...)
+	buf = append(buf, ':')
+	return itoa(buf, frame.Line, -1)
+}
+
+// callerFrame returns the frame of the caller, skip is the same as in runtime.Caller
+// as if it was called by the function calling callerFrame.
+func callerFrame(skip int) runtime.Frame {
+	var pcs [1]uintptr
+	if runtime.Callers(skip+2, pcs[:]) == 0 {
+		return runtime.Frame{File: "???"}
+	}
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	return frame
+}
+
+// itoa appends the decimal of i to buf, zero padded to wid digits.
+// It is copied from the standard package log.
+func itoa(buf []byte, i int, wid int) []byte {
+	// Assemble decimal in reverse order.
+	var b [20]byte
+	bp := len(b) - 1
+	for i >= 10 || wid > 1 {
+		wid--
+		q := i / 10
+		b[bp] = byte('0' + i - q*10)
+		bp--
+		i = q
+	}
+	// i < 10
+	b[bp] = byte('0' + i)
+	return append(buf, b[bp:]...)
+}
@@ -0,0 +1,106 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultExitTimeout is the default timeout of exit handlers.
+const DefaultExitTimeout = 5 * time.Second
+
+// exiter decides how a LeveledLogger exits on FATA logs, it's shared between a LeveledLogger and its children.
+type exiter struct {
+	mu       sync.Mutex
+	exitFunc func(int)
+	code     int
+	timeout  time.Duration
+	handlers []func()
+}
+
+func newExiter() *exiter {
+	return &exiter{exitFunc: os.Exit, code: 1, timeout: DefaultExitTimeout}
+}
+
+// SetExitFunc sets the function called with the exit code after FATA logs, which is os.Exit by default.
+// Fatal* return if exitFunc returns, which is useful in tests.
+// It's shared between l and its children.
+func (l *LeveledLogger) SetExitFunc(exitFunc func(code int)) {
+	l.exiter.mu.Lock()
+	defer l.exiter.mu.Unlock()
+
+	if exitFunc == nil {
+		exitFunc = os.Exit
+	}
+	l.exiter.exitFunc = exitFunc
+}
+
+// SetExitCode sets the code to exit with after FATA logs, which is 1 by default.
+// It's shared between l and its children.
+func (l *LeveledLogger) SetExitCode(code int) {
+	l.exiter.mu.Lock()
+	defer l.exiter.mu.Unlock()
+
+	l.exiter.code = code
+}
+
+// SetExitTimeout sets how long to wait for exit handlers before exiting, which is DefaultExitTimeout by default.
+// A non-positive timeout waits until all the handlers return.
+// It's shared between l and its children.
+func (l *LeveledLogger) SetExitTimeout(timeout time.Duration) {
+	l.exiter.mu.Lock()
+	defer l.exiter.mu.Unlock()
+
+	l.exiter.timeout = timeout
+}
+
+// RegisterExitHandler registers handler to be called before exiting on FATA logs, e.g. to flush buffers or close files.
+// Handlers are called in the order they are registered, panics of them are recovered and reported to os.Stderr.
+// After handlers return or time out, l is closed then the exit function is called.
+// It's shared between l and its children.
+func (l *LeveledLogger) RegisterExitHandler(handler func()) {
+	l.exiter.mu.Lock()
+	defer l.exiter.mu.Unlock()
+
+	l.exiter.handlers = append(l.exiter.handlers, handler)
+}
+
+// exit runs the exit handlers, closes l then calls the exit function.
+func (x *exiter) exit(l *LeveledLogger) {
+	x.mu.Lock()
+	exitFunc, code, timeout := x.exitFunc, x.code, x.timeout
+	handlers := append([]func(){}, x.handlers...)
+	x.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, handler := range handlers {
+			runExitHandler(handler)
+		}
+	}()
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		select {
+		case <-done:
+		case <-timer.C:
+			fmt.Fprintf(errorOutput, "log: exit handlers timed out after %v\n", timeout)
+		}
+		timer.Stop()
+	} else {
+		<-done
+	}
+
+	_ = l.Close()
+	exitFunc(code)
+}
+
+func runExitHandler(handler func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(errorOutput, "log: exit handler panicked: %v\n", r)
+		}
+	}()
+	handler()
+}
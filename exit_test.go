@@ -0,0 +1,93 @@
+package log
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFatalExit(t *testing.T) {
+	var buf, errBuf bytes.Buffer
+	errorOutput = &errBuf
+	defer func() { errorOutput = os.Stderr }()
+
+	l := NewLeveledLoggerWithColor(&buf, 0, false)
+	var calls []string
+	code := -1
+	l.SetExitFunc(func(c int) {
+		calls = append(calls, "exit")
+		code = c
+	})
+	l.SetExitCode(3)
+	l.RegisterExitHandler(func() {
+		l.Info("flushing")
+		calls = append(calls, "flush")
+	})
+	l.With("k", "v").(*LeveledLogger).RegisterExitHandler(func() { panic("boom") })
+	l.RegisterExitHandler(func() { calls = append(calls, "close") })
+
+	l.Fatalf("fatal %d", 1)
+	if exp := []string{"flush", "close", "exit"}; !reflect.DeepEqual(calls, exp) {
+		t.Errorf("Expected calls %v, got %v", exp, calls)
+	}
+	if code != 3 {
+		t.Errorf("Expected exit code 3, got %d", code)
+	}
+	if exp := "F fatal 1\nI flushing\n"; buf.String() != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, buf.String())
+	}
+	if exp := "log: exit handler panicked: boom\n"; errBuf.String() != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, errBuf.String())
+	}
+}
+
+func TestFatalExitTimeout(t *testing.T) {
+	var errBuf bytes.Buffer
+	errorOutput = &errBuf
+	defer func() { errorOutput = os.Stderr }()
+
+	l := NewLeveledLoggerWithColor(&bytes.Buffer{}, 0, false)
+	exited := false
+	l.SetExitFunc(func(int) { exited = true })
+	l.SetExitTimeout(10 * time.Millisecond)
+	block := make(chan struct{})
+	defer close(block)
+	l.RegisterExitHandler(func() { <-block })
+
+	l.Fatal("fatal")
+	if !exited {
+		t.Error("Expected to exit")
+	}
+	if !strings.Contains(errBuf.String(), "timed out after 10ms") {
+		t.Errorf("Unexpected error output '%s'", errBuf.String())
+	}
+}
+
+func TestPanic(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLeveledLoggerWithColor(&buf, Lshortfile, false)
+
+	recovered := func(f func()) (r interface{}) {
+		defer func() { r = recover() }()
+		f()
+		return nil
+	}
+	if r := recovered(func() { l.Panicf("panic %d", 1) }); r != "panic 1" {
+		t.Errorf("Expected to panic with 'panic 1', got %v", r)
+	}
+	if exp := "P exit_test.go:78: panic 1\n"; buf.String() != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, buf.String())
+	}
+
+	buf.Reset()
+	l.SetOutputLevel(FATA)
+	if r := recovered(func() { l.Panic("filtered") }); r != "filtered" {
+		t.Errorf("Expected to panic with 'filtered', got %v", r)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Expected no output, got '%s'", buf.String())
+	}
+}
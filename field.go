@@ -0,0 +1,150 @@
+package log
+
+import (
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+)
+
+// badKey is used as the key of a value which is not preceded by a string key.
+const badKey = "!BADKEY"
+
+// Field is a key/value pair bound to a logger by With or WithFields.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// F creates a Field with given key and value.
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// fieldsFromKeyvals converts alternating keys and values into Fields.
+// A Field in keyvals is used as is, a value without a string key is keyed by "!BADKEY".
+func fieldsFromKeyvals(keyvals []interface{}) []Field {
+	fields := make([]Field, 0, (len(keyvals)+1)/2)
+	for i := 0; i < len(keyvals); i++ {
+		switch k := keyvals[i].(type) {
+		case Field:
+			fields = append(fields, k)
+		case string:
+			if i+1 < len(keyvals) {
+				fields = append(fields, Field{Key: k, Value: keyvals[i+1]})
+				i++
+			} else {
+				fields = append(fields, Field{Key: badKey, Value: k})
+			}
+		default:
+			fields = append(fields, Field{Key: badKey, Value: k})
+		}
+	}
+	return fields
+}
+
+// appendFields appends fields in the form of " key=value" to buf.
+// Values containing spaces, quotes, equal signs or control characters are quoted,
+// while such characters in keys are replaced by underscores since quoted keys are not valid logfmt.
+func appendFields(buf []byte, fields []Field) []byte {
+	for _, f := range fields {
+		buf = append(buf, ' ')
+		buf = appendLogfmtKey(buf, f.Key)
+		buf = append(buf, '=')
+		buf = appendLogfmtValue(buf, f.Value)
+	}
+	return buf
+}
+
+func appendLogfmtValue(buf []byte, v interface{}) []byte {
+	switch v := v.(type) {
+	case nil:
+		return append(buf, "nil"...)
+	case string:
+		return appendLogfmtString(buf, v)
+	case bool:
+		return strconv.AppendBool(buf, v)
+	case int:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int8:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int16:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int32:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(buf, v, 10)
+	case uint:
+		return strconv.AppendUint(buf, uint64(v), 10)
+	case uint8:
+		return strconv.AppendUint(buf, uint64(v), 10)
+	case uint16:
+		return strconv.AppendUint(buf, uint64(v), 10)
+	case uint32:
+		return strconv.AppendUint(buf, uint64(v), 10)
+	case uint64:
+		return strconv.AppendUint(buf, v, 10)
+	case float32:
+		return strconv.AppendFloat(buf, float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.AppendFloat(buf, v, 'g', -1, 64)
+	case error:
+		return appendLogfmtString(buf, v.Error())
+	case fmt.Stringer:
+		return appendLogfmtString(buf, v.String())
+	default:
+		return appendLogfmtString(buf, fmt.Sprint(v))
+	}
+}
+
+// appendLogfmtKey appends key to buf with characters needing quoting replaced by underscores,
+// an empty key is appended as an underscore.
+func appendLogfmtKey(buf []byte, key string) []byte {
+	if key == "" {
+		return append(buf, '_')
+	}
+	for _, r := range key {
+		if needsQuotingRune(r) {
+			buf = append(buf, '_')
+		} else {
+			buf = utf8.AppendRune(buf, r)
+		}
+	}
+	return buf
+}
+
+// appendLogfmtString appends s to buf, quoting it if necessary.
+func appendLogfmtString(buf []byte, s string) []byte {
+	if !needsQuoting(s) {
+		return append(buf, s...)
+	}
+	return strconv.AppendQuote(buf, s)
+}
+
+func needsQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if needsQuotingRune(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func needsQuotingRune(r rune) bool {
+	return r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || r == 0x7f
+}
+
+// fieldString returns the string of a field value without quoting.
+func fieldString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,47 @@
+package log
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFieldsFromKeyvals(t *testing.T) {
+	fields := fieldsFromKeyvals([]interface{}{"a", 1, F("b", 2), 3, "c"})
+	exp := []Field{{"a", 1}, {"b", 2}, {badKey, 3}, {badKey, "c"}}
+	if len(fields) != len(exp) {
+		t.Fatalf("Expected %d fields, got %d: %v", len(exp), len(fields), fields)
+	}
+	for i := range exp {
+		if fields[i] != exp[i] {
+			t.Errorf("Expected field %v, got %v", exp[i], fields[i])
+		}
+	}
+}
+
+func TestAppendFields(t *testing.T) {
+	cases := []struct {
+		field Field
+		exp   string
+	}{
+		{F("k", "v"), " k=v"},
+		{F("k", ""), ` k=""`},
+		{F("k", "a b"), ` k="a b"`},
+		{F("k", "a=b"), ` k="a=b"`},
+		{F("k", `a"b`), ` k="a\"b"`},
+		{F("k", "a\nb"), ` k="a\nb"`},
+		{F("k", 42), " k=42"},
+		{F("k", 1.5), " k=1.5"},
+		{F("k", true), " k=true"},
+		{F("k", nil), " k=nil"},
+		{F("k", errors.New("oops")), " k=oops"},
+		{F("a key", 1), " a_key=1"},
+		{F(`a="b"`, 1), " a__b_=1"},
+		{F("a\tb\xff", 1), " a_b_=1"},
+		{F("", 1), " _=1"},
+	}
+	for _, c := range cases {
+		if got := string(appendFields(nil, []Field{c.field})); got != c.exp {
+			t.Errorf("Expected '%s', got '%s'", c.exp, got)
+		}
+	}
+}
@@ -22,3 +22,10 @@ const (
 	LUTC                          // if Ldate or Ltime is set, use UTC rather than the local time zone
 	LstdFlags     = Ldate | Ltime // initial values for the standard logger
 )
+
+// Flags specific to this package, they start after Lmsgprefix of the standard package log to avoid conflicts.
+const (
+	Lstack      = 1 << (iota + 7) // stack trace of logs at or above the stack level, see LeveledLogger.SetStackLevel
+	Lfunction                     // function name of the caller: pkg.(*Type).Method
+	Lmodulefile                   // file name relative to the module root and line number: c/d.go:23. overrides Llongfile and Lshortfile
+)
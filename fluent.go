@@ -0,0 +1,403 @@
+package log
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// FluentFormatter formats Entries as MessagePack encoded [time, record] of the Fluent Forward protocol, see FluentWriter.
+//
+// Time is encoded as EventTime with nanoseconds. Keys of the record are the same as those of JSONFormatter
+// except that time is not in the record, Flag decides which keys are present as in NewJSONLogger.
+// Bound fields conflicting with the keys are prefixed by "fields." as well.
+// Values of bound fields are encoded as numbers, booleans or nil, other types are converted to strings.
+type FluentFormatter struct {
+	Flag int
+}
+
+// Format implements Formatter.
+func (f *FluentFormatter) Format(buf []byte, e *Entry) []byte {
+	t := e.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	n := 2 + len(e.Fields)
+	if e.Name != "" {
+		n++
+	}
+	if hasFile(f.Flag) {
+		n++
+	}
+	if f.Flag&Lfunction != 0 {
+		n++
+	}
+	if e.Stack != "" {
+		n++
+	}
+
+	buf = appendMsgpackArrayHeader(buf, 2)
+	buf = appendMsgpackEventTime(buf, t)
+	buf = appendMsgpackMapHeader(buf, n)
+	buf = appendMsgpackString(buf, "level")
+	buf = appendMsgpackString(buf, e.Level.String())
+	if e.Name != "" {
+		buf = appendMsgpackString(buf, "logger")
+		buf = appendMsgpackString(buf, e.Name)
+	}
+	if hasFile(f.Flag) {
+		buf = appendMsgpackString(buf, "caller")
+		buf = appendMsgpackString(buf, string(appendCaller(nil, e.Caller, f.Flag)))
+	}
+	if f.Flag&Lfunction != 0 {
+		buf = appendMsgpackString(buf, "func")
+		buf = appendMsgpackString(buf, funcName(e.Caller.Function))
+	}
+	buf = appendMsgpackString(buf, "msg")
+	buf = appendMsgpackString(buf, e.Message)
+	for _, field := range e.Fields {
+		buf = appendMsgpackString(buf, jsonFieldKey(field.Key))
+		buf = appendMsgpackValue(buf, field.Value)
+	}
+	if e.Stack != "" {
+		buf = appendMsgpackString(buf, "stack")
+		buf = appendMsgpackString(buf, e.Stack)
+	}
+	return buf
+}
+
+// FluentOptions are options of FluentWriter, zero values are replaced by defaults.
+type FluentOptions struct {
+	// Tag is the tag of events, which is the base name of the program by default.
+	Tag string
+	// RequireAck indicates if acknowledgements of chunks are required, chunks not acknowledged are resent.
+	RequireAck bool
+	// AckTimeout is the timeout of sending a batch, and of waiting for its acknowledgement, which is 5 seconds by default.
+	AckTimeout time.Duration
+	// BufferLimit is the maximum number of events buffered, the oldest ones are dropped when it's exceeded.
+	// It's 8192 by default.
+	BufferLimit int
+	// BatchSize is the maximum number of events sent in a PackedForward message, which is 256 by default.
+	BatchSize int
+	// RetryWait is the interval of retrying when the collector is unavailable, which is 1 second by default.
+	RetryWait time.Duration
+}
+
+// FluentWriter is an io.Writer which sends each Write as an event to Fluentd or Fluent Bit with the Forward protocol,
+// it's usually used with FluentFormatter, e.g.
+//
+//	w := log.NewFluentWriter("tcp", "localhost:24224", log.FluentOptions{Tag: "app", RequireAck: true})
+//	logger := log.NewLeveledLoggerWithFormatter(w, log.Lshortfile, &log.FluentFormatter{Flag: log.Lshortfile})
+//
+// Events are buffered and sent in background in PackedForward mode, batched as they accumulate.
+// While the collector is unavailable, events are kept in the buffer and sending is retried.
+// Failures are reported to os.Stderr once until sending succeeds again.
+//
+// It's safe for concurrent use. Close should be called before the program exits to send the buffered events,
+// e.g. by RegisterExitHandler for FATA logs.
+type FluentWriter struct {
+	network string
+	addr    string
+	opts    FluentOptions
+
+	mu      sync.Mutex
+	pending [][]byte
+	closed  bool
+	dropped uint64
+
+	notify chan struct{}
+	stop   chan struct{}
+	done   chan struct{}
+	err    error
+
+	// Fields below are only accessed by the background goroutine.
+	conn    net.Conn
+	failing bool
+}
+
+// NewFluentWriter creates a FluentWriter sending to addr of network, which is "tcp" or "unix".
+// It connects in background, thus never fails.
+func NewFluentWriter(network, addr string, opts FluentOptions) *FluentWriter {
+	w := newFluentWriter(network, addr, opts)
+	go w.run()
+	return w
+}
+
+// newFluentWriter creates a FluentWriter without starting the background goroutine.
+func newFluentWriter(network, addr string, opts FluentOptions) *FluentWriter {
+	if opts.Tag == "" {
+		opts.Tag = filepath.Base(os.Args[0])
+	}
+	if opts.AckTimeout <= 0 {
+		opts.AckTimeout = 5 * time.Second
+	}
+	if opts.BufferLimit <= 0 {
+		opts.BufferLimit = 8192
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 256
+	}
+	if opts.RetryWait <= 0 {
+		opts.RetryWait = time.Second
+	}
+	return &FluentWriter{
+		network: network,
+		addr:    addr,
+		opts:    opts,
+		notify:  make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Write buffers a copy of p as an event, which is expected to be a MessagePack encoded [time, record].
+func (w *FluentWriter) Write(p []byte) (int, error) {
+	event := make([]byte, len(p))
+	copy(event, p)
+
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return 0, os.ErrClosed
+	}
+	if len(w.pending) >= w.opts.BufferLimit {
+		w.pending[0] = nil
+		w.pending = w.pending[1:]
+		atomic.AddUint64(&w.dropped, 1)
+	}
+	w.pending = append(w.pending, event)
+	w.mu.Unlock()
+
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+	return len(p), nil
+}
+
+// Dropped returns the number of events dropped due to the BufferLimit, or failures on Close.
+func (w *FluentWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&w.dropped)
+}
+
+// Close tries sending the buffered events once then closes the connection,
+// the error of sending is returned if the events are not all sent.
+func (w *FluentWriter) Close() error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.stop)
+	}
+	w.mu.Unlock()
+
+	<-w.done
+	return w.err
+}
+
+func (w *FluentWriter) run() {
+	defer close(w.done)
+
+	var batch [][]byte
+	for {
+		if batch == nil {
+			batch = w.take()
+		}
+		if batch == nil {
+			select {
+			case <-w.notify:
+				continue
+			case <-w.stop:
+				w.err = w.drain(nil)
+				return
+			}
+		}
+		if err := w.send(batch); err != nil {
+			w.report(err)
+			select {
+			case <-time.After(w.opts.RetryWait):
+				continue
+			case <-w.stop:
+				w.err = w.drain(batch)
+				return
+			}
+		}
+		w.failing = false
+		batch = nil
+	}
+}
+
+// take removes at most BatchSize events from the buffer, nil is returned if there is none.
+func (w *FluentWriter) take() [][]byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.pending) == 0 {
+		return nil
+	}
+	n := min(len(w.pending), w.opts.BatchSize)
+	batch := make([][]byte, n)
+	copy(batch, w.pending)
+	w.pending = w.pending[n:]
+	if len(w.pending) == 0 {
+		w.pending = nil
+	}
+	return batch
+}
+
+// drain tries sending batch and the buffered events once, then closes the connection.
+func (w *FluentWriter) drain(batch [][]byte) error {
+	var err error
+	if batch == nil {
+		batch = w.take()
+	}
+	for ; batch != nil; batch = w.take() {
+		if err == nil {
+			err = w.send(batch)
+		}
+		if err != nil {
+			atomic.AddUint64(&w.dropped, uint64(len(batch)))
+		}
+	}
+	if w.conn != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+	return err
+}
+
+func (w *FluentWriter) report(err error) {
+	if !w.failing {
+		w.failing = true
+		fmt.Fprintf(errorOutput, "log: failed to send to %s %s, retrying: %v\n", w.network, w.addr, err)
+	}
+}
+
+// send sends batch as a PackedForward message, and waits for the acknowledgement if required.
+// The connection is closed on failure.
+func (w *FluentWriter) send(batch [][]byte) error {
+	if w.conn == nil {
+		conn, err := net.DialTimeout(w.network, w.addr, dialTimeout)
+		if err != nil {
+			return err
+		}
+		w.conn = conn
+	}
+	err := w.sendMessage(batch)
+	if err != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+	return err
+}
+
+func (w *FluentWriter) sendMessage(batch [][]byte) error {
+	size := 0
+	for _, event := range batch {
+		size += len(event)
+	}
+	msg := make([]byte, 0, size+len(w.opts.Tag)+64)
+	msg = appendMsgpackArrayHeader(msg, 3)
+	msg = appendMsgpackString(msg, w.opts.Tag)
+	msg = appendMsgpackBinaryHeader(msg, size)
+	for _, event := range batch {
+		msg = append(msg, event...)
+	}
+	var chunk string
+	if w.opts.RequireAck {
+		var id [16]byte
+		if _, err := rand.Read(id[:]); err != nil {
+			return err
+		}
+		chunk = base64.StdEncoding.EncodeToString(id[:])
+		msg = appendMsgpackMapHeader(msg, 2)
+		msg = appendMsgpackString(msg, "chunk")
+		msg = appendMsgpackString(msg, chunk)
+	} else {
+		msg = appendMsgpackMapHeader(msg, 1)
+	}
+	msg = appendMsgpackString(msg, "size")
+	msg = appendMsgpackUint(msg, uint64(len(batch)))
+
+	// A collector which stops reading must not block sending forever, nor Close.
+	if err := w.conn.SetWriteDeadline(time.Now().Add(w.opts.AckTimeout)); err != nil {
+		return err
+	}
+	if _, err := w.conn.Write(msg); err != nil {
+		return err
+	}
+	if !w.opts.RequireAck {
+		return nil
+	}
+
+	if err := w.conn.SetReadDeadline(time.Now().Add(w.opts.AckTimeout)); err != nil {
+		return err
+	}
+	ack, err := readFluentAck(bufio.NewReader(w.conn))
+	if err != nil {
+		return err
+	}
+	if ack != chunk {
+		return errUnexpectedAck
+	}
+	return nil
+}
+
+var errUnexpectedAck = errors.New("log: unexpected acknowledgement")
+
+// readFluentAck reads a response {"ack": chunk} and returns the chunk.
+// Only maps of short strings are accepted, lengths from the collector are never trusted for allocation.
+func readFluentAck(r *bufio.Reader) (string, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	if b&0xf0 != 0x80 {
+		return "", errUnexpectedAck
+	}
+	var ack string
+	for n := int(b & 0x0f); n > 0; n-- {
+		key, err := readFluentAckString(r)
+		if err != nil {
+			return "", err
+		}
+		value, err := readFluentAckString(r)
+		if err != nil {
+			return "", err
+		}
+		if key == "ack" {
+			ack = value
+		}
+	}
+	return ack, nil
+}
+
+// readFluentAckString reads a fixstr or str8, which is at most 255 bytes.
+func readFluentAckString(r *bufio.Reader) (string, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	n := int(b & 0x1f)
+	if b == 0xd9 {
+		if b, err = r.ReadByte(); err != nil {
+			return "", err
+		}
+		n = int(b)
+	} else if b&0xe0 != 0xa0 {
+		return "", errUnexpectedAck
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
@@ -0,0 +1,439 @@
+package log
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// msgpackExt is a decoded MessagePack extension.
+type msgpackExt struct {
+	Type int8
+	Data []byte
+}
+
+// readMsgpack decodes a MessagePack value from r for the mock collector. Maps are decoded as map[string]interface{},
+// strings and binaries as string, integers as int64 or uint64, and extensions as msgpackExt.
+func readMsgpack(r *bufio.Reader) (interface{}, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case b <= 0x7f:
+		return int64(b), nil
+	case b >= 0xe0:
+		return int64(int8(b)), nil
+	case b&0xf0 == 0x80:
+		return readMsgpackMap(r, int(b&0x0f))
+	case b&0xf0 == 0x90:
+		return readMsgpackArray(r, int(b&0x0f))
+	case b&0xe0 == 0xa0:
+		return readMsgpackString(r, int(b&0x1f))
+	}
+
+	switch b {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xd9:
+		n, err := readMsgpackUint(r, 1)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackString(r, int(n))
+	case 0xc5, 0xda:
+		n, err := readMsgpackUint(r, 2)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackString(r, int(n))
+	case 0xc6, 0xdb:
+		n, err := readMsgpackUint(r, 4)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackString(r, int(n))
+	case 0xca:
+		u, err := readMsgpackUint(r, 4)
+		return float64(math.Float32frombits(uint32(u))), err
+	case 0xcb:
+		u, err := readMsgpackUint(r, 8)
+		return math.Float64frombits(u), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		return readMsgpackUint(r, 1<<(b-0xcc))
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (b - 0xd0)
+		u, err := readMsgpackUint(r, size)
+		return int64(u<<(64-8*size)) >> (64 - 8*size), err
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return readMsgpackExt(r, 1<<(b-0xd4))
+	case 0xdc:
+		n, err := readMsgpackUint(r, 2)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackArray(r, int(n))
+	case 0xdd:
+		n, err := readMsgpackUint(r, 4)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackArray(r, int(n))
+	case 0xde:
+		n, err := readMsgpackUint(r, 2)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackMap(r, int(n))
+	case 0xdf:
+		n, err := readMsgpackUint(r, 4)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackMap(r, int(n))
+	}
+	return nil, fmt.Errorf("log: unsupported MessagePack type 0x%x", b)
+}
+
+func readMsgpackUint(r *bufio.Reader, size int) (uint64, error) {
+	var b [8]byte
+	if _, err := io.ReadFull(r, b[8-size:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(b[:]), nil
+}
+
+func readMsgpackString(r *bufio.Reader, n int) (string, error) {
+	b := make([]byte, n)
+	_, err := io.ReadFull(r, b)
+	return string(b), err
+}
+
+func readMsgpackExt(r *bufio.Reader, n int) (msgpackExt, error) {
+	t, err := r.ReadByte()
+	if err != nil {
+		return msgpackExt{}, err
+	}
+	data := make([]byte, n)
+	_, err = io.ReadFull(r, data)
+	return msgpackExt{Type: int8(t), Data: data}, err
+}
+
+func readMsgpackArray(r *bufio.Reader, n int) ([]interface{}, error) {
+	a := make([]interface{}, n)
+	for i := range a {
+		v, err := readMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		a[i] = v
+	}
+	return a, nil
+}
+
+func readMsgpackMap(r *bufio.Reader, n int) (map[string]interface{}, error) {
+	m := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		k, err := readMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		v, err := readMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		m[fmt.Sprint(k)] = v
+	}
+	return m, nil
+}
+
+// fluentEvent is an event received by fluentServer.
+type fluentEvent struct {
+	tag    string
+	time   msgpackExt
+	record map[string]interface{}
+}
+
+// fluentServer accepts connections from ln and decodes PackedForward messages into events,
+// acknowledgements are sent if requested.
+func fluentServer(t *testing.T, ln net.Listener) <-chan fluentEvent {
+	events := make(chan fluentEvent, 64)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				r := bufio.NewReader(conn)
+				for {
+					msg, err := readMsgpack(r)
+					if err != nil {
+						if !errors.Is(err, io.EOF) {
+							t.Error(err)
+						}
+						return
+					}
+					forward := msg.([]interface{})
+					tag, entries, opts := forward[0].(string), forward[1].(string), forward[2].(map[string]interface{})
+					er := bufio.NewReader(strings.NewReader(entries))
+					var n uint64
+					for ; ; n++ {
+						entry, err := readMsgpack(er)
+						if err == io.EOF {
+							break
+						}
+						if err != nil {
+							t.Error(err)
+							return
+						}
+						pair := entry.([]interface{})
+						events <- fluentEvent{tag, pair[0].(msgpackExt), pair[1].(map[string]interface{})}
+					}
+					if size := fmt.Sprint(opts["size"]); size != fmt.Sprint(n) {
+						t.Errorf("Expected size %d, got %s", n, size)
+					}
+					if chunk, ok := opts["chunk"].(string); ok {
+						ack := appendMsgpackMapHeader(nil, 1)
+						ack = appendMsgpackString(ack, "ack")
+						ack = appendMsgpackString(ack, chunk)
+						if _, err := conn.Write(ack); err != nil {
+							t.Error(err)
+							return
+						}
+					}
+				}
+			}()
+		}
+	}()
+	return events
+}
+
+func receiveFluent(t *testing.T, events <-chan fluentEvent) fluentEvent {
+	t.Helper()
+	select {
+	case e := <-events:
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected an event, got none")
+	}
+	return fluentEvent{}
+}
+
+func TestFluentWriter(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ln.Close() }()
+	events := fluentServer(t, ln)
+
+	w := NewFluentWriter("tcp", ln.Addr().String(), FluentOptions{Tag: "app.test", RequireAck: true, BatchSize: 2})
+	logger := NewLeveledLoggerWithFormatter(w, LstdFlags|Lshortfile, &FluentFormatter{Flag: Lshortfile})
+	logger.SetClock(func() time.Time { return time.Unix(1232670203, 123456789) })
+
+	logger.With(F("n", 1), F("ok", true), F("err", errors.New("boom")), F("msg", "m")).Info("first")
+	logger.Warn("second")
+	logger.Error("third")
+
+	e := receiveFluent(t, events)
+	if e.tag != "app.test" {
+		t.Errorf("Expected tag 'app.test', got '%s'", e.tag)
+	}
+	if want := (msgpackExt{0, []byte{0x49, 0x79, 0x0d, 0xfb, 0x07, 0x5b, 0xcd, 0x15}}); e.time.Type != want.Type || string(e.time.Data) != string(want.Data) {
+		t.Errorf("Expected time %v, got %v", want, e.time)
+	}
+	want := map[string]interface{}{"level": "INFO", "caller": "fluent_test.go:250", "msg": "first", "n": int64(1), "ok": true, "err": "boom", "fields.msg": "m"}
+	if len(e.record) != len(want) {
+		t.Errorf("Expected record %v, got %v", want, e.record)
+	}
+	for k, v := range want {
+		if e.record[k] != v {
+			t.Errorf("Expected %s %#v, got %#v", k, v, e.record[k])
+		}
+	}
+	for _, msg := range []string{"second", "third"} {
+		if e := receiveFluent(t, events); e.record["msg"] != msg {
+			t.Errorf("Expected '%s', got '%v'", msg, e.record["msg"])
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		t.Error(err)
+	}
+	if _, err := w.Write([]byte{0x90}); err != os.ErrClosed {
+		t.Errorf("Expected %v from Write after Close, got %v", os.ErrClosed, err)
+	}
+}
+
+func TestFluentWriterRetry(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix socket")
+	}
+	defer func(w io.Writer) { errorOutput = w }(errorOutput)
+	var errs syncBuffer
+	errorOutput = &errs
+
+	addr := filepath.Join(t.TempDir(), "fluent.sock")
+	w := NewFluentWriter("unix", addr, FluentOptions{RequireAck: true, BufferLimit: 3, RetryWait: 10 * time.Millisecond})
+	logger := NewLeveledLoggerWithFormatter(w, 0, &FluentFormatter{})
+	for _, msg := range []string{"a", "b", "c", "d"} {
+		logger.Info(msg)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	ln, err := net.Listen("unix", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ln.Close() }()
+	events := fluentServer(t, ln)
+
+	var got []string
+	for len(got) < 3 {
+		got = append(got, receiveFluent(t, events).record["msg"].(string))
+	}
+	// The first event may be taken for sending before the buffer is full.
+	if got := strings.Join(got, ""); got != "bcd" && got != "acd" {
+		t.Errorf("Expected 'bcd' or 'acd', got '%s'", got)
+	}
+	if n := w.Dropped(); n != 1 {
+		t.Errorf("Expected 1 dropped, got %d", n)
+	}
+	if n := strings.Count(errs.String(), "retrying"); n != 1 {
+		t.Errorf("Expected 1 failure reported, got '%s'", errs.String())
+	}
+	if err := w.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFluentWriterClose(t *testing.T) {
+	defer func(w io.Writer) { errorOutput = w }(errorOutput)
+	errorOutput = io.Discard
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	w := NewFluentWriter("tcp", addr, FluentOptions{RetryWait: time.Hour})
+	if _, err := w.Write(appendMsgpackArrayHeader(nil, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err == nil {
+		t.Error("Expected Close to fail")
+	}
+	if n := w.Dropped(); n != 1 {
+		t.Errorf("Expected 1 dropped, got %d", n)
+	}
+}
+
+func TestFluentWriterCloseImmediately(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ln.Close() }()
+	events := fluentServer(t, ln)
+
+	// Events written after the background goroutine found the buffer empty are sent on Close.
+	w := newFluentWriter("tcp", ln.Addr().String(), FluentOptions{})
+	if _, err := w.Write((&FluentFormatter{}).Format(nil, &Entry{Level: INFO, Message: "pending"})); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.drain(nil); err != nil {
+		t.Fatal(err)
+	}
+	if e := receiveFluent(t, events); e.record["msg"] != "pending" {
+		t.Errorf("Expected 'pending', got '%v'", e.record["msg"])
+	}
+
+	for i := 0; i < 20; i++ {
+		w := NewFluentWriter("tcp", ln.Addr().String(), FluentOptions{RequireAck: true})
+		logger := NewLeveledLoggerWithFormatter(w, 0, &FluentFormatter{})
+		logger.Info("last words")
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if n := w.Dropped(); n != 0 {
+			t.Fatalf("Expected 0 dropped, got %d", n)
+		}
+		if e := receiveFluent(t, events); e.record["msg"] != "last words" {
+			t.Errorf("Expected 'last words', got '%v'", e.record["msg"])
+		}
+	}
+}
+
+func TestFluentWriterStalled(t *testing.T) {
+	defer func(w io.Writer) { errorOutput = w }(errorOutput)
+	errorOutput = io.Discard
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ln.Close() }()
+	// The collector accepts but never reads.
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			<-t.Context().Done()
+			_ = conn.Close()
+		}
+	}()
+
+	w := NewFluentWriter("tcp", ln.Addr().String(), FluentOptions{AckTimeout: 50 * time.Millisecond, RetryWait: time.Hour})
+	event := make([]byte, 1<<20)
+	for i := 0; i < 64; i++ {
+		if _, err := w.Write(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	closed := make(chan error)
+	go func() { closed <- w.Close() }()
+	select {
+	case err := <-closed:
+		if err == nil {
+			t.Error("Expected Close to fail")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close blocked by a stalled collector")
+	}
+}
+
+func TestReadFluentAck(t *testing.T) {
+	for _, c := range []struct {
+		in  string
+		ack string
+		err bool
+	}{
+		{"\x81\xa3ack\xa4abcd", "abcd", false},
+		{"\x82\xa1k\xa1v\xa3ack\xd9\x02ab", "ab", false},
+		{"\x81\xa3ack\xdb\xff\xff\xff\xff", "", true},
+		{"\xdf\xff\xff\xff\xff", "", true},
+		{"\x81\xa3ack", "", true},
+	} {
+		ack, err := readFluentAck(bufio.NewReader(strings.NewReader(c.in)))
+		if ack != c.ack || (err != nil) != c.err {
+			t.Errorf("Expected '%s' and error %v from %q, got '%s' and %v", c.ack, c.err, c.in, ack, err)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package log
+
+import "strings"
+
+// Formatter formats Entries into bytes.
+type Formatter interface {
+	// Format appends the formatted e to buf and returns the extended buffer.
+	Format(buf []byte, e *Entry) []byte
+}
+
+// TextFormatter formats Entries in the layout of the standard package log prefixed by the initial of the level, e.g.
+//
+//	D 2009/01/23 01:23:23 d.go:23: a message key=value
+//
+// The function name is rendered after the file name and line number if Lfunction is set, e.g. "d.go:23 main.(*T).Run: ".
+// The name of the logger is rendered in brackets before the message if there is one,
+// the stack trace is rendered on the following lines indented by tabs if there is one.
+//
+// Flag is the same as the flag of log.New, Colored indicates if the prefix is colored.
+// TimeLayout replaces the layout of Ldate, Ltime and Lmicroseconds if it's not empty,
+// which is one of the Time* layouts or a layout of package time, e.g. time.RFC3339Nano.
+type TextFormatter struct {
+	Flag       int
+	Colored    bool
+	TimeLayout string
+}
+
+// Format implements Formatter.
+func (f *TextFormatter) Format(buf []byte, e *Entry) []byte {
+	prefix, color := levelPrefix(e.Level)
+	if f.Colored {
+		buf = append(buf, color...)
+		buf = append(buf, prefix...)
+		buf = append(buf, ColorRST...)
+	} else {
+		buf = append(buf, prefix...)
+	}
+	if hasTime(f.Flag) {
+		buf = appendTime(buf, e.Time, f.Flag, f.TimeLayout)
+		buf = append(buf, ' ')
+	}
+	if hasFile(f.Flag) {
+		buf = appendCaller(buf, e.Caller, f.Flag)
+		if f.Flag&Lfunction != 0 {
+			buf = append(buf, ' ')
+		}
+	}
+	if f.Flag&Lfunction != 0 {
+		buf = append(buf, funcName(e.Caller.Function)...)
+	}
+	if hasCaller(f.Flag) {
+		buf = append(buf, ": "...)
+	}
+	if e.Name != "" {
+		buf = append(buf, '[')
+		buf = append(buf, e.Name...)
+		buf = append(buf, "] "...)
+	}
+	buf = append(buf, e.Message...)
+	buf = appendFields(buf, e.Fields)
+	buf = append(buf, '\n')
+	if e.Stack != "" {
+		buf = appendIndented(buf, e.Stack)
+	}
+	return buf
+}
+
+// appendIndented appends each line of s to buf prefixed by a tab.
+func appendIndented(buf []byte, s string) []byte {
+	for s != "" {
+		line := s
+		if i := strings.IndexByte(s, '\n'); i >= 0 {
+			line, s = s[:i], s[i+1:]
+		} else {
+			s = ""
+		}
+		buf = append(buf, '\t')
+		buf = append(buf, line...)
+		buf = append(buf, '\n')
+	}
+	return buf
+}
+
+func levelPrefix(level Level) (prefix string, color string) {
+	switch level {
+	case DEBUG:
+		return "D ", ColorBlue
+	case INFO:
+		return "I ", ColorGreen
+	case WARN:
+		return "W ", ColorYellow
+	case ERROR:
+		return "E ", ColorMegenta
+	case PANIC:
+		return "P ", ColorRed
+	case FATA:
+		return "F ", ColorRed
+	}
+	return "", ""
+}
@@ -0,0 +1,85 @@
+package log
+
+import (
+	"bytes"
+	"log"
+	"testing"
+	"time"
+)
+
+func TestTextFormatterCompatibility(t *testing.T) {
+	output := func(std *log.Logger, ours *LeveledLogger, msg string) {
+		_ = std.Output(2, msg)
+		ours.InfoDepth(1, msg)
+	}
+	for _, flag := range []int{0, Lshortfile, Llongfile, Lshortfile | Llongfile} {
+		for _, msg := range []string{"", "msg", "msg\n", "msg\n\n", "multi\nline"} {
+			var std, ours bytes.Buffer
+			output(log.New(&std, "I ", flag), NewLeveledLoggerWithColor(&ours, flag, false), msg)
+			if ours.String() != std.String() {
+				t.Errorf("Flag %d msg %q: expected '%s', got '%s'", flag, msg, std.String(), ours.String())
+			}
+		}
+	}
+}
+
+func TestTextFormatterTime(t *testing.T) {
+	e := &Entry{
+		Time:    time.Date(2009, 1, 23, 1, 23, 23, 123123123, time.FixedZone("", 3600)),
+		Level:   DEBUG,
+		Message: "msg",
+	}
+	cases := []struct {
+		flag int
+		exp  string
+	}{
+		{Ldate, "D 2009/01/23 msg\n"},
+		{Ltime, "D 01:23:23 msg\n"},
+		{LstdFlags, "D 2009/01/23 01:23:23 msg\n"},
+		{Lmicroseconds, "D 01:23:23.123123 msg\n"},
+		{LstdFlags | Lmicroseconds | LUTC, "D 2009/01/23 00:23:23.123123 msg\n"},
+	}
+	for _, c := range cases {
+		f := &TextFormatter{Flag: c.flag}
+		if got := string(f.Format(nil, e)); got != c.exp {
+			t.Errorf("Flag %d: expected '%s', got '%s'", c.flag, c.exp, got)
+		}
+	}
+}
+
+func TestTextFormatterColored(t *testing.T) {
+	f := &TextFormatter{Colored: true}
+	got := string(f.Format(nil, &Entry{Level: WARN, Message: "msg", Fields: []Field{F("k", "v")}}))
+	if exp := ColorYellow + "W " + ColorRST + "msg k=v\n"; got != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, got)
+	}
+}
+
+func TestTimeLayout(t *testing.T) {
+	now := time.Date(2009, 1, 23, 1, 23, 23, 123456789, time.FixedZone("X", 3600))
+	clock := func() time.Time { return now }
+
+	var buf bytes.Buffer
+	l := NewLeveledLoggerWithFormatter(&buf, Ltime|LUTC, &TextFormatter{Flag: Ltime | LUTC, TimeLayout: time.RFC3339Nano})
+	l.SetClock(clock)
+	l.Info("text")
+	if exp := "I 2009-01-23T00:23:23.123456789Z text\n"; buf.String() != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, buf.String())
+	}
+
+	buf.Reset()
+	l = NewLeveledLoggerWithFormatter(&buf, Ltime, &JSONFormatter{Flag: Ltime, TimeLayout: TimeUnixMilli})
+	l.SetClock(clock)
+	l.With("k", "v").Info("json")
+	if exp := `{"time":1232670203123,"level":"INFO","msg":"json","k":"v"}` + "\n"; buf.String() != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, buf.String())
+	}
+
+	buf.Reset()
+	l = NewLeveledLoggerWithFormatter(&buf, Ltime, &LogfmtFormatter{Flag: Ltime, TimeLayout: "2006-01-02 15:04:05"})
+	l.SetClock(clock)
+	l.Info("logfmt")
+	if exp := `level=info ts="2009-01-23 01:23:23" msg=logfmt` + "\n"; buf.String() != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, buf.String())
+	}
+}
@@ -0,0 +1,291 @@
+package log
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// GELFFormatter formats Entries as GELF 1.1 messages of Graylog, see GELFWriter.
+//
+// Levels are mapped by SyslogSeverity, the stack trace is sent as full_message if there is one.
+// The caller is sent as _file and _line, and _function if Lfunction is set, the name of the logger as _logger.
+// Bound fields are sent as additional fields prefixed by an underscore, e.g. "request_id" as _request_id,
+// whose keys are sanitized as required, and values are numbers or strings.
+// Keys conflicting with the fields above or the reserved _id are prefixed by another underscore, e.g. "file" as __file.
+type GELFFormatter struct {
+	Flag int
+	// Host is the host sending messages, which is the host name of the machine if empty.
+	Host string
+}
+
+var localHostname, _ = os.Hostname()
+
+// Format implements Formatter.
+func (f *GELFFormatter) Format(buf []byte, e *Entry) []byte {
+	host := f.Host
+	if host == "" {
+		host = localHostname
+	}
+	t := e.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+
+	buf = append(buf, `{"version":"1.1","host":`...)
+	buf = appendJSONString(buf, host)
+	buf = append(buf, `,"short_message":`...)
+	buf = appendJSONString(buf, e.Message)
+	if e.Stack != "" {
+		buf = append(buf, `,"full_message":`...)
+		buf = appendJSONString(buf, e.Message+"\n"+e.Stack)
+	}
+	buf = append(buf, `,"timestamp":`...)
+	buf = strconv.AppendFloat(buf, float64(t.UnixMilli())/1e3, 'f', 3, 64)
+	buf = append(buf, `,"level":`...)
+	buf = strconv.AppendInt(buf, int64(SyslogSeverity(e.Level)), 10)
+	if hasFile(f.Flag) {
+		buf = append(buf, `,"_file":`...)
+		buf = appendJSONString(buf, e.Caller.File)
+		buf = append(buf, `,"_line":`...)
+		buf = strconv.AppendInt(buf, int64(e.Caller.Line), 10)
+	}
+	if f.Flag&Lfunction != 0 {
+		buf = append(buf, `,"_function":`...)
+		buf = appendJSONString(buf, e.Caller.Function)
+	}
+	if e.Name != "" {
+		buf = append(buf, `,"_logger":`...)
+		buf = appendJSONString(buf, e.Name)
+	}
+	for _, field := range e.Fields {
+		buf = append(buf, ',')
+		buf = appendJSONString(buf, gelfFieldName(field.Key))
+		buf = append(buf, ':')
+		buf = appendGELFValue(buf, field.Value)
+	}
+	return append(buf, '}')
+}
+
+// gelfReservedFields are the additional fields reserved by GELF or written by GELFFormatter itself.
+var gelfReservedFields = map[string]bool{
+	"_id":       true,
+	"_file":     true,
+	"_line":     true,
+	"_function": true,
+	"_logger":   true,
+}
+
+// gelfFieldName returns the name of the additional field of key,
+// characters other than letters, digits, underscores, dashes and dots are replaced by underscores.
+// Reserved names are prefixed by another underscore, e.g. key "id" is renamed to "__id".
+func gelfFieldName(key string) string {
+	name := make([]byte, 0, len(key)+2)
+	name = append(name, '_')
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-' || c == '.' {
+			name = append(name, c)
+		} else {
+			name = append(name, '_')
+		}
+	}
+	if gelfReservedFields[string(name)] {
+		return "_" + string(name)
+	}
+	return string(name)
+}
+
+// appendGELFValue appends v as a JSON number if it's a number, as a JSON string otherwise.
+func appendGELFValue(buf []byte, v interface{}) []byte {
+	switch v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return appendJSONValue(buf, v)
+	}
+	return appendJSONString(buf, fieldString(v))
+}
+
+// GELFCompression is the compression of GELF messages over UDP.
+type GELFCompression int
+
+// GELF compressions.
+const (
+	GELFCompressNone GELFCompression = iota
+	GELFCompressGzip
+	GELFCompressZlib
+)
+
+// GELF chunking.
+const (
+	// DefaultGELFChunkSize is the default maximum size of UDP datagrams including headers of chunks.
+	DefaultGELFChunkSize = 1420
+	gelfChunkHeaderSize  = 12
+	gelfMaxChunks        = 128
+)
+
+// GELFOptions are options of GELFWriter.
+type GELFOptions struct {
+	// Compression is the compression of messages over UDP, messages over TCP are not compressed.
+	Compression GELFCompression
+	// ChunkSize is the maximum size of UDP datagrams, larger messages are chunked.
+	// It's DefaultGELFChunkSize if not positive.
+	ChunkSize int
+}
+
+// GELFWriter is an io.Writer which sends each Write as a GELF message to Graylog over UDP or TCP,
+// it's usually used with GELFFormatter, e.g.
+//
+//	w, err := log.NewGELFWriter("udp", "graylog:12201", log.GELFOptions{Compression: log.GELFCompressGzip})
+//	if err != nil {
+//		// handle err
+//	}
+//	logger := log.NewLeveledLoggerWithFormatter(w, log.Lshortfile, &log.GELFFormatter{Flag: log.Lshortfile})
+//
+// Messages are compressed and chunked over UDP, while they are delimited by null bytes over TCP.
+// The connection is reestablished if a write fails. It's safe for concurrent use.
+type GELFWriter struct {
+	network string
+	addr    string
+	opts    GELFOptions
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewGELFWriter connects to Graylog at addr of network, which is "udp" or "tcp".
+func NewGELFWriter(network, addr string, opts GELFOptions) (*GELFWriter, error) {
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = DefaultGELFChunkSize
+	}
+	if opts.ChunkSize <= gelfChunkHeaderSize {
+		return nil, errors.New("log: GELF chunk size is too small")
+	}
+	w := &GELFWriter{network: network, addr: addr, opts: opts}
+	if err := w.connect(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write sends p as a GELF message, it reconnects and retries once on failure.
+func (w *GELFWriter) Write(p []byte) (int, error) {
+	msgs, err := w.frame(p)
+	if err != nil {
+		return 0, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn != nil {
+		if err := writeAll(w.conn, msgs); err == nil {
+			return len(p), nil
+		}
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+	if err := w.connect(); err != nil {
+		return 0, err
+	}
+	if err := writeAll(w.conn, msgs); err != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the connection, it's reestablished on the next Write.
+func (w *GELFWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+func (w *GELFWriter) connect() error {
+	conn, err := net.DialTimeout(w.network, w.addr, dialTimeout)
+	if err != nil {
+		return err
+	}
+	w.conn = conn
+	return nil
+}
+
+// frame returns the packets of p to send, which are compressed and chunked for UDP, or null-terminated for TCP.
+func (w *GELFWriter) frame(p []byte) ([][]byte, error) {
+	if isStream(w.network) {
+		msg := make([]byte, len(p)+1)
+		copy(msg, p)
+		return [][]byte{msg}, nil
+	}
+
+	msg, err := compressGELF(p, w.opts.Compression)
+	if err != nil {
+		return nil, err
+	}
+	if len(msg) <= w.opts.ChunkSize {
+		return [][]byte{msg}, nil
+	}
+	size := w.opts.ChunkSize - gelfChunkHeaderSize
+	count := (len(msg) + size - 1) / size
+	if count > gelfMaxChunks {
+		return nil, errors.New("log: GELF message is too large")
+	}
+	var id [8]byte
+	for i := range id {
+		id[i] = byte(rand.Uint32())
+	}
+	chunks := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		chunk := make([]byte, 0, w.opts.ChunkSize)
+		chunk = append(chunk, 0x1e, 0x0f)
+		chunk = append(chunk, id[:]...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, msg[i*size:min((i+1)*size, len(msg))]...)
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
+
+func compressGELF(p []byte, compression GELFCompression) ([]byte, error) {
+	var buf bytes.Buffer
+	var zw io.WriteCloser
+	switch compression {
+	case GELFCompressGzip:
+		zw = gzip.NewWriter(&buf)
+	case GELFCompressZlib:
+		zw = zlib.NewWriter(&buf)
+	default:
+		return p, nil
+	}
+	if _, err := zw.Write(p); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeAll(w io.Writer, packets [][]byte) error {
+	for _, p := range packets {
+		if _, err := w.Write(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
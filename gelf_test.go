@@ -0,0 +1,142 @@
+package log
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"math/rand/v2"
+	"net"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGELFFormatter(t *testing.T) {
+	e := &Entry{
+		Time:    time.Unix(1232673803, 123456789),
+		Level:   ERROR,
+		Caller:  runtime.Frame{File: "/a/b/c/d.go", Line: 23, Function: "main.main"},
+		Message: "a message",
+		Fields: []Field{
+			{Key: "id", Value: 1}, {Key: "user name", Value: "u"}, {Key: "ok", Value: true},
+			{Key: "file", Value: "f"}, {Key: "line", Value: 2}, {Key: "function", Value: "g"}, {Key: "logger", Value: "l"},
+		},
+		Name:  "app",
+		Stack: "main.main\n\t/a/b/c/d.go:23",
+	}
+	f := &GELFFormatter{Flag: Lshortfile | Lfunction, Host: "host"}
+	exp := `{"version":"1.1","host":"host","short_message":"a message",` +
+		`"full_message":"a message\nmain.main\n\t/a/b/c/d.go:23","timestamp":1232673803.123,"level":3,` +
+		`"_file":"/a/b/c/d.go","_line":23,"_function":"main.main","_logger":"app",` +
+		`"__id":1,"_user_name":"u","_ok":"true","__file":"f","__line":2,"__function":"g","__logger":"l"}`
+	if got := string(f.Format(nil, e)); got != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, got)
+	}
+}
+
+func TestGELFWriterUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	receive := func() []byte {
+		t.Helper()
+		buf := make([]byte, 2048)
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return buf[:n]
+	}
+
+	w, err := NewGELFWriter("udp", conn.LocalAddr().String(), GELFOptions{Compression: GELFCompressGzip, ChunkSize: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = w.Close() }()
+	l := NewLeveledLoggerWithFormatter(w, 0, &GELFFormatter{Host: "host"})
+
+	// Random content is hardly compressible thus chunked.
+	r := rand.New(rand.NewPCG(1, 2))
+	letters := make([]byte, 1000)
+	for i := range letters {
+		letters[i] = byte('a' + r.IntN(26))
+	}
+	msg := string(letters)
+	l.Info(msg)
+
+	first := receive()
+	if first[0] != 0x1e || first[1] != 0x0f {
+		t.Fatalf("Expected chunks, got %x", first)
+	}
+	count := int(first[11])
+	chunks := make([][]byte, count)
+	chunks[first[10]] = first[12:]
+	for i := 1; i < count; i++ {
+		chunk := receive()
+		if string(chunk[2:10]) != string(first[2:10]) {
+			t.Fatalf("Expected message ID %x, got %x", first[2:10], chunk[2:10])
+		}
+		chunks[chunk[10]] = chunk[12:]
+	}
+	var data []byte
+	for _, chunk := range chunks {
+		data = append(data, chunk...)
+	}
+	zr, err := gzip.NewReader(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err = io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatalf("Invalid JSON '%s': %v", data, err)
+	}
+	if m["short_message"] != msg || m["level"] != float64(6) {
+		t.Errorf("Unexpected message %v", m)
+	}
+}
+
+func TestGELFWriterTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ln.Close() }()
+
+	w, err := NewGELFWriter("tcp", ln.Addr().String(), GELFOptions{Compression: GELFCompressGzip})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = w.Close() }()
+	l := NewLeveledLoggerWithFormatter(w, 0, &GELFFormatter{Host: "host"})
+	l.Warn("first")
+	l.With("k", "v").Warn("second")
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	r := bufio.NewReader(conn)
+	for _, exp := range []string{"first", "second"} {
+		data, err := r.ReadBytes(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var m map[string]interface{}
+		if err := json.Unmarshal(data[:len(data)-1], &m); err != nil {
+			t.Fatalf("Invalid JSON '%s': %v", data, err)
+		}
+		if m["short_message"] != exp || m["level"] != float64(4) {
+			t.Errorf("Unexpected message %v", m)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package log
+
+import (
+	"io"
+	"sync"
+)
+
+// handler handles Entries which passed OutputLevel and hooks.
+type handler interface {
+	handle(e *Entry)
+	// setAsync makes the handler write through AsyncWriters.
+	setAsync(size int, policy OverflowPolicy)
+	flush() error
+	close() error
+	dropped() uint64
+}
+
+// writerHandler writes Entries formatted by formatter to w.
+// Writes are serialized, it is shared between a LeveledLogger and its children.
+type writerHandler struct {
+	formatter Formatter
+	mu        sync.Mutex
+	w         io.Writer
+	async     *AsyncWriter
+}
+
+func (h *writerHandler) handle(e *Entry) {
+	buf := bufferPool.Get().(*[]byte)
+	*buf = h.formatter.Format((*buf)[:0], e)
+	h.mu.Lock()
+	_, _ = h.w.Write(*buf)
+	h.mu.Unlock()
+	bufferPool.Put(buf)
+}
+
+func (h *writerHandler) setAsync(size int, policy OverflowPolicy) {
+	if h.async == nil {
+		h.async = NewAsyncWriter(h.w, size, policy)
+		h.w = h.async
+	}
+}
+
+func (h *writerHandler) flush() error {
+	if h.async == nil {
+		return nil
+	}
+	return h.async.Flush()
+}
+
+func (h *writerHandler) close() error {
+	if h.async == nil {
+		return nil
+	}
+	return h.async.Close()
+}
+
+func (h *writerHandler) dropped() uint64 {
+	if h.async == nil {
+		return 0
+	}
+	return h.async.Dropped()
+}
+
+// LevelWriter is a writer for logs of levels from Min to Max inclusive.
+// Max of NOTSET means there is no upper bound.
+//
+// Logs are formatted by Formatter, or by a TextFormatter colored if Writer is a colored terminal when Formatter is nil.
+type LevelWriter struct {
+	Min       Level
+	Max       Level
+	Writer    io.Writer
+	Formatter Formatter
+}
+
+// covers reports whether logs of level are written to w.
+func (w LevelWriter) covers(level Level) bool {
+	return level >= w.Min && (w.Max == NOTSET || level <= w.Max)
+}
+
+// NewLeveledLoggerWithWriters creates a LeveledLogger which writes logs to the writers covering their levels,
+// a log is written to multiple writers if their levels overlap, or discarded if no writer covers its level.
+// The flag argument is the same as NewLeveledLogger, it's also used by the default TextFormatter of each writer.
+//
+// For example, the following logs DEBUG and INFO to stdout, WARN and above to stderr,
+// and additionally ERROR and above to a file:
+//
+//	NewLeveledLoggerWithWriters(LstdFlags,
+//		LevelWriter{Min: DEBUG, Max: INFO, Writer: os.Stdout},
+//		LevelWriter{Min: WARN, Writer: os.Stderr},
+//		LevelWriter{Min: ERROR, Writer: file},
+//	)
+func NewLeveledLoggerWithWriters(flag int, writers ...LevelWriter) *LeveledLogger {
+	h := &levelWritersHandler{}
+	for _, w := range writers {
+		f := w.Formatter
+		if f == nil {
+			f = &TextFormatter{Flag: flag, Colored: IsColoredTerminal(w.Writer)}
+		}
+		wh := &writerHandler{w: w.Writer, formatter: f}
+		h.writers = append(h.writers, wh)
+		for level := DEBUG; level <= FATA; level++ {
+			if w.covers(level) {
+				h.byLevel[level] = append(h.byLevel[level], wh)
+			}
+		}
+	}
+	return newLeveledLogger(h, flag)
+}
+
+// levelWritersHandler dispatches Entries to the writers of their levels.
+type levelWritersHandler struct {
+	writers []*writerHandler
+	byLevel [FATA + 1][]*writerHandler
+}
+
+func (h *levelWritersHandler) handle(e *Entry) {
+	for _, wh := range h.byLevel[e.Level] {
+		wh.handle(e)
+	}
+}
+
+func (h *levelWritersHandler) setAsync(size int, policy OverflowPolicy) {
+	for _, wh := range h.writers {
+		wh.setAsync(size, policy)
+	}
+}
+
+func (h *levelWritersHandler) flush() error {
+	var err error
+	for _, wh := range h.writers {
+		if e := wh.flush(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+func (h *levelWritersHandler) close() error {
+	var err error
+	for _, wh := range h.writers {
+		if e := wh.close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+func (h *levelWritersHandler) dropped() (n uint64) {
+	for _, wh := range h.writers {
+		n += wh.dropped()
+	}
+	return n
+}
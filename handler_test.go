@@ -0,0 +1,34 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLeveledLoggerWithWriters(t *testing.T) {
+	var stdout, stderr, file bytes.Buffer
+	l := NewLeveledLoggerWithWriters(0,
+		LevelWriter{Min: DEBUG, Max: INFO, Writer: &stdout},
+		LevelWriter{Min: WARN, Writer: &stderr},
+		LevelWriter{Min: ERROR, Writer: &file, Formatter: &LogfmtFormatter{}},
+	)
+	l.Debug("debug")
+	l.Info("info")
+	l.Warn("warn")
+	l.Error("error")
+
+	cases := []struct {
+		name string
+		buf  *bytes.Buffer
+		exp  string
+	}{
+		{"stdout", &stdout, "D debug\nI info\n"},
+		{"stderr", &stderr, "W warn\nE error\n"},
+		{"file", &file, "level=error msg=error\n"},
+	}
+	for _, c := range cases {
+		if c.buf.String() != c.exp {
+			t.Errorf("Expected '%s' in %s, got '%s'", c.exp, c.name, c.buf.String())
+		}
+	}
+}
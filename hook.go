@@ -0,0 +1,70 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// Hook is fired on each Entry of the Levels it is interested in.
+//
+// Fire is called synchronously after the Entry passes OutputLevel and before it is written,
+// it may modify the Entry except the elements of Fields which are shared with the logger,
+// and must not retain the Entry after returning.
+// An error returned or a panic raised by Fire is reported to os.Stderr,
+// the Entry is still written and the remaining hooks are still fired.
+type Hook interface {
+	Levels() []Level
+	Fire(*Entry) error
+}
+
+// errorOutput is where errors of hooks and exit handlers are reported.
+var errorOutput io.Writer = os.Stderr
+
+// hooks are shared between a LeveledLogger and its children.
+// They are copied on write so firing does not require locking.
+type hooks struct {
+	mu      sync.Mutex
+	byLevel atomic.Value // map[Level][]Hook
+}
+
+func (h *hooks) add(hook Hook) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	old, _ := h.byLevel.Load().(map[Level][]Hook)
+	byLevel := make(map[Level][]Hook, len(old))
+	for level, hooks := range old {
+		byLevel[level] = hooks
+	}
+	for _, level := range hook.Levels() {
+		hooks := make([]Hook, 0, len(byLevel[level])+1)
+		hooks = append(hooks, byLevel[level]...)
+		byLevel[level] = append(hooks, hook)
+	}
+	h.byLevel.Store(byLevel)
+}
+
+func (h *hooks) get(level Level) []Hook {
+	byLevel, _ := h.byLevel.Load().(map[Level][]Hook)
+	return byLevel[level]
+}
+
+func fireHooks(hooks []Hook, e *Entry) {
+	for _, hook := range hooks {
+		fireHook(hook, e)
+	}
+}
+
+func fireHook(hook Hook, e *Entry) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(errorOutput, "log: hook %T panicked: %v\n", hook, r)
+		}
+	}()
+	if err := hook.Fire(e); err != nil {
+		fmt.Fprintf(errorOutput, "log: hook %T failed: %v\n", hook, err)
+	}
+}
@@ -0,0 +1,76 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+type testHook struct {
+	levels  []Level
+	entries []Entry
+	err     error
+	panic   bool
+}
+
+func (h *testHook) Levels() []Level {
+	return h.levels
+}
+
+func (h *testHook) Fire(e *Entry) error {
+	if h.panic {
+		panic("boom")
+	}
+	h.entries = append(h.entries, *e)
+	return h.err
+}
+
+func TestHook(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLeveledLoggerWithColor(&buf, 0, false)
+	l.SetOutputLevel(INFO)
+	hook := &testHook{levels: []Level{DEBUG, WARN, ERROR}}
+	l.AddHook(hook)
+
+	l.Debug("filtered by output level")
+	l.Info("not interested")
+	l.Warn("warn")
+	l.With("k", "v").Errorf("error %d", 1)
+
+	if len(hook.entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d: %v", len(hook.entries), hook.entries)
+	}
+	if e := hook.entries[0]; e.Level != WARN || e.Message != "warn" || e.Time.IsZero() {
+		t.Errorf("Unexpected entry: %v", e)
+	}
+	if e := hook.entries[1]; e.Level != ERROR || e.Message != "error 1" || len(e.Fields) != 1 {
+		t.Errorf("Unexpected entry: %v", e)
+	}
+}
+
+func TestHookFailure(t *testing.T) {
+	var buf, errBuf bytes.Buffer
+	errorOutput = &errBuf
+	defer func() { errorOutput = os.Stderr }()
+
+	l := NewLeveledLoggerWithColor(&buf, 0, false)
+	ok := &testHook{levels: []Level{INFO}}
+	l.AddHook(&testHook{levels: []Level{INFO}, err: errors.New("oops")})
+	l.AddHook(&testHook{levels: []Level{INFO}, panic: true})
+	l.AddHook(ok)
+	l.Info("still logged")
+
+	if buf.String() != "I still logged\n" {
+		t.Errorf("Log is expected despite of failed hooks: '%s'", buf.String())
+	}
+	if len(ok.entries) != 1 {
+		t.Errorf("Hooks after the failed ones are expected to be fired")
+	}
+	for _, exp := range []string{"failed: oops", "panicked: boom"} {
+		if !strings.Contains(errBuf.String(), exp) {
+			t.Errorf("Expected '%s' not found in: '%s'", exp, errBuf.String())
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package log
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// JournalSocket is the socket of systemd-journald for the native protocol.
+const JournalSocket = "/run/systemd/journal/socket"
+
+// JournalFormatter formats Entries in the native protocol of systemd-journald, see JournalWriter.
+//
+// Fields are MESSAGE, PRIORITY mapped by SyslogSeverity, SYSLOG_IDENTIFIER, CODE_FILE, CODE_LINE and CODE_FUNC
+// if the caller is collected, LOGGER if the logger is named, and STACK_TRACE if there is one.
+// Keys of bound fields are converted to uppercase journal field names, e.g. "request-id" to REQUEST_ID,
+// while those can not be converted are dropped. Those conflicting with the fields above are prefixed by FIELD_,
+// e.g. "message" to FIELD_MESSAGE.
+type JournalFormatter struct {
+	// Identifier is SYSLOG_IDENTIFIER, which is the base name of the program if empty.
+	Identifier string
+}
+
+// Format implements Formatter.
+func (f *JournalFormatter) Format(buf []byte, e *Entry) []byte {
+	identifier := f.Identifier
+	if identifier == "" {
+		identifier = filepath.Base(os.Args[0])
+	}
+	buf = appendJournalField(buf, "MESSAGE", e.Message)
+	buf = appendJournalField(buf, "PRIORITY", strconv.Itoa(SyslogSeverity(e.Level)))
+	buf = appendJournalField(buf, "SYSLOG_IDENTIFIER", identifier)
+	if e.Caller.File != "" {
+		buf = appendJournalField(buf, "CODE_FILE", e.Caller.File)
+		buf = appendJournalField(buf, "CODE_LINE", strconv.Itoa(e.Caller.Line))
+	}
+	if e.Caller.Function != "" {
+		buf = appendJournalField(buf, "CODE_FUNC", e.Caller.Function)
+	}
+	if e.Name != "" {
+		buf = appendJournalField(buf, "LOGGER", e.Name)
+	}
+	if e.Stack != "" {
+		buf = appendJournalField(buf, "STACK_TRACE", e.Stack)
+	}
+	for _, field := range e.Fields {
+		if name := journalFieldName(field.Key); name != "" {
+			buf = appendJournalField(buf, name, fieldString(field.Value))
+		}
+	}
+	return buf
+}
+
+// appendJournalField appends a field to buf, values with newlines are prefixed by their sizes in binary.
+func appendJournalField(buf []byte, name string, value string) []byte {
+	buf = append(buf, name...)
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\n' {
+			buf = append(buf, '\n')
+			buf = binary.LittleEndian.AppendUint64(buf, uint64(len(value)))
+			buf = append(buf, value...)
+			return append(buf, '\n')
+		}
+	}
+	buf = append(buf, '=')
+	buf = append(buf, value...)
+	return append(buf, '\n')
+}
+
+// journalReservedFields are the fields written by JournalFormatter itself.
+var journalReservedFields = map[string]bool{
+	"MESSAGE":           true,
+	"PRIORITY":          true,
+	"SYSLOG_IDENTIFIER": true,
+	"CODE_FILE":         true,
+	"CODE_LINE":         true,
+	"CODE_FUNC":         true,
+	"LOGGER":            true,
+	"STACK_TRACE":       true,
+}
+
+// journalFieldName converts key to a journal field name, which consists of uppercase letters, digits and underscores,
+// starting with a letter. Reserved names are prefixed by FIELD_, an empty string is returned if key can not be converted.
+func journalFieldName(key string) string {
+	name := make([]byte, 0, len(key))
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case c >= 'a' && c <= 'z':
+			name = append(name, c-'a'+'A')
+		case c >= 'A' && c <= 'Z', c >= '0' && c <= '9' && len(name) > 0:
+			name = append(name, c)
+		case len(name) > 0:
+			name = append(name, '_')
+		}
+	}
+	if journalReservedFields[string(name)] {
+		name = append([]byte("FIELD_"), name...)
+	}
+	// Names are limited to 64 characters by journald.
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return string(name)
+}
@@ -0,0 +1,118 @@
+//go:build linux
+// +build linux
+
+package log
+
+import (
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// JournalWriter is an io.Writer which sends each Write as a datagram to systemd-journald,
+// it's usually used with JournalFormatter, e.g.
+//
+//	w, err := log.NewJournalWriter("")
+//	if err != nil {
+//		// handle err
+//	}
+//	logger := log.NewLeveledLoggerWithFormatter(w, log.Lshortfile, &log.JournalFormatter{})
+//
+// Writes too large for datagrams are passed through sealed memfds, or unlinked files in /dev/shm
+// if memfds are not supported, as sd_journal_send does. It's safe for concurrent use.
+type JournalWriter struct {
+	addr *net.UnixAddr
+
+	mu   sync.Mutex
+	conn *net.UnixConn
+}
+
+// NewJournalWriter creates a JournalWriter sending to the socket at path, which is JournalSocket if empty.
+func NewJournalWriter(path string) (*JournalWriter, error) {
+	if path == "" {
+		path = JournalSocket
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	// The socket is not connected so that it keeps working after journald restarts.
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	return &JournalWriter{addr: &net.UnixAddr{Name: path, Net: "unixgram"}, conn: conn}, nil
+}
+
+// Write sends p to journald.
+func (w *JournalWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return 0, os.ErrClosed
+	}
+	_, err := w.conn.WriteToUnix(p, w.addr)
+	if errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS) {
+		err = w.writeFile(p)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the socket.
+func (w *JournalWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// writeFile writes p to a memfd or a temporary file and sends its descriptor to journald.
+func (w *JournalWriter) writeFile(p []byte) error {
+	f, err := journalFile(p)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	_, _, err = w.conn.WriteMsgUnix(nil, unix.UnixRights(int(f.Fd())), w.addr)
+	return err
+}
+
+func journalFile(p []byte) (*os.File, error) {
+	if fd, err := unix.MemfdCreate("journal-message", unix.MFD_CLOEXEC|unix.MFD_ALLOW_SEALING); err == nil {
+		f := os.NewFile(uintptr(fd), "journal-message")
+		if _, err := f.Write(p); err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		// journald only accepts sealed memfds.
+		if _, err := unix.FcntlInt(f.Fd(), unix.F_ADD_SEALS, unix.F_SEAL_SHRINK|unix.F_SEAL_GROW|unix.F_SEAL_WRITE|unix.F_SEAL_SEAL); err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		return f, nil
+	}
+
+	f, err := os.CreateTemp("/dev/shm", "journal-")
+	if err != nil {
+		return nil, err
+	}
+	_ = os.Remove(f.Name())
+	if _, err := f.Write(p); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return f, nil
+}
@@ -0,0 +1,118 @@
+//go:build linux
+// +build linux
+
+package log
+
+import (
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// parseJournal parses data in the native protocol of journald.
+func parseJournal(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	fields := make(map[string]string)
+	for len(data) > 0 {
+		i := strings.IndexAny(string(data), "=\n")
+		if i < 0 {
+			t.Fatalf("Malformed data %q", data)
+		}
+		name := string(data[:i])
+		if data[i] == '=' {
+			end := strings.IndexByte(string(data), '\n')
+			fields[name] = string(data[i+1 : end])
+			data = data[end+1:]
+			continue
+		}
+		size := binary.LittleEndian.Uint64(data[i+1:])
+		fields[name] = string(data[i+9 : i+9+int(size)])
+		data = data[i+9+int(size)+1:]
+	}
+	return fields
+}
+
+func TestJournalWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "socket")
+	ln, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ln.Close() }()
+
+	w, err := NewJournalWriter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = w.Close() }()
+	l := NewLeveledLoggerWithFormatter(w, Lshortfile, &JournalFormatter{Identifier: "app"})
+
+	receive := func() map[string]string {
+		t.Helper()
+		buf := make([]byte, 1<<16)
+		oob := make([]byte, 64)
+		_ = ln.SetReadDeadline(time.Now().Add(time.Second))
+		n, oobn, _, _, err := ln.ReadMsgUnix(buf, oob)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if oobn == 0 {
+			return parseJournal(t, buf[:n])
+		}
+		msgs, err := unix.ParseSocketControlMessage(oob[:oobn])
+		if err != nil {
+			t.Fatal(err)
+		}
+		fds, err := unix.ParseUnixRights(&msgs[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		f := os.NewFile(uintptr(fds[0]), "memfd")
+		defer func() { _ = f.Close() }()
+		info, err := f.Stat()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data := make([]byte, info.Size())
+		if _, err := f.ReadAt(data, 0); err != nil {
+			t.Fatal(err)
+		}
+		return parseJournal(t, data)
+	}
+
+	l.With("request-id", 42, "_trusted", "x", "9lives", true, "message", "m", "code_file", "f").Warn("line 1\nline 2")
+	fields := receive()
+	exp := map[string]string{
+		"MESSAGE":           "line 1\nline 2",
+		"PRIORITY":          "4",
+		"SYSLOG_IDENTIFIER": "app",
+		"CODE_FILE":         fields["CODE_FILE"],
+		"CODE_LINE":         "89",
+		"CODE_FUNC":         "github.com/tevino/log.TestJournalWriter",
+		"REQUEST_ID":        "42",
+		"TRUSTED":           "x",
+		"LIVES":             "true",
+		"FIELD_MESSAGE":     "m",
+		"FIELD_CODE_FILE":   "f",
+	}
+	if !strings.HasSuffix(fields["CODE_FILE"], "/journal_linux_test.go") || len(fields) != len(exp) {
+		t.Errorf("Unexpected fields %v", fields)
+	}
+	for k, v := range exp {
+		if fields[k] != v {
+			t.Errorf("Expected %s=%q, got %q", k, v, fields[k])
+		}
+	}
+
+	large := strings.Repeat("x", 1<<20)
+	l.Error(large)
+	if fields := receive(); fields["MESSAGE"] != large || fields["PRIORITY"] != "3" {
+		t.Errorf("Unexpected fields of large message: PRIORITY=%s, len(MESSAGE)=%d", fields["PRIORITY"], len(fields["MESSAGE"]))
+	}
+}
@@ -0,0 +1,24 @@
+//go:build !linux
+// +build !linux
+
+package log
+
+import "errors"
+
+// JournalWriter sends logs to systemd-journald, which is only supported on Linux.
+type JournalWriter struct{}
+
+// NewJournalWriter returns an error since systemd-journald is only supported on Linux.
+func NewJournalWriter(path string) (*JournalWriter, error) {
+	return nil, errors.New("log: journald is only supported on Linux")
+}
+
+// Write returns an error since systemd-journald is only supported on Linux.
+func (w *JournalWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("log: journald is only supported on Linux")
+}
+
+// Close does nothing.
+func (w *JournalWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,198 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"unicode/utf8"
+)
+
+// NewJSONLogger creates a LeveledLogger which writes one JSON object per line to out.
+// The flag argument decides which keys are emitted: "time" if any of Ldate, Ltime and Lmicroseconds is set,
+// "caller" if any of Lshortfile, Llongfile and Lmodulefile is set, "func" if Lfunction is set, "logger" if the logger is named,
+// while "level" and "msg" are always emitted, followed by bound fields, then "stack" if the stack trace is captured.
+// Bound fields whose keys are among the keys above are prefixed by "fields.", e.g. "msg" as "fields.msg",
+// so that they never override the keys of the log itself.
+func NewJSONLogger(out io.Writer, flag int) *LeveledLogger {
+	return NewLeveledLoggerWithFormatter(out, flag, &JSONFormatter{Flag: flag})
+}
+
+// JSONFormatter formats Entries as JSON objects.
+// Flag decides which keys are emitted as in NewJSONLogger, TimeLayout is the same as in TextFormatter.
+// Times are rendered as numbers in Time* layouts, as strings otherwise.
+type JSONFormatter struct {
+	Flag       int
+	TimeLayout string
+}
+
+// Format implements Formatter.
+func (j *JSONFormatter) Format(buf []byte, e *Entry) []byte {
+	buf = append(buf, '{')
+	if hasTime(j.Flag) {
+		buf = append(buf, `"time":`...)
+		switch {
+		case isUnixTime(j.TimeLayout):
+			buf = appendTime(buf, e.Time, j.Flag, j.TimeLayout)
+		case j.TimeLayout == "":
+			buf = append(buf, '"')
+			buf = appendTime(buf, e.Time, j.Flag, "")
+			buf = append(buf, '"')
+		default:
+			buf = appendJSONString(buf, string(appendTime(nil, e.Time, j.Flag, j.TimeLayout)))
+		}
+		buf = append(buf, ',')
+	}
+	buf = append(buf, `"level":"`...)
+	buf = append(buf, e.Level.String()...)
+	buf = append(buf, `",`...)
+	if e.Name != "" {
+		buf = append(buf, `"logger":`...)
+		buf = appendJSONString(buf, e.Name)
+		buf = append(buf, ',')
+	}
+	if hasFile(j.Flag) {
+		buf = append(buf, `"caller":`...)
+		buf = appendJSONString(buf, string(appendCaller(nil, e.Caller, j.Flag)))
+		buf = append(buf, ',')
+	}
+	if j.Flag&Lfunction != 0 {
+		buf = append(buf, `"func":`...)
+		buf = appendJSONString(buf, funcName(e.Caller.Function))
+		buf = append(buf, ',')
+	}
+	buf = append(buf, `"msg":`...)
+	buf = appendJSONString(buf, e.Message)
+	for _, f := range e.Fields {
+		buf = append(buf, ',')
+		buf = appendJSONString(buf, jsonFieldKey(f.Key))
+		buf = append(buf, ':')
+		buf = appendJSONValue(buf, f.Value)
+	}
+	if e.Stack != "" {
+		buf = append(buf, `,"stack":`...)
+		buf = appendJSONString(buf, e.Stack)
+	}
+	return append(buf, '}', '\n')
+}
+
+// jsonReservedKeys are the keys of logs written by JSONFormatter and FluentFormatter.
+var jsonReservedKeys = map[string]bool{
+	"time":   true,
+	"level":  true,
+	"logger": true,
+	"caller": true,
+	"func":   true,
+	"msg":    true,
+	"stack":  true,
+}
+
+// jsonFieldKey returns the key of a bound field, which is prefixed by "fields." if it's reserved.
+func jsonFieldKey(key string) string {
+	if jsonReservedKeys[key] {
+		return "fields." + key
+	}
+	return key
+}
+
+func appendJSONValue(buf []byte, v interface{}) []byte {
+	switch v := v.(type) {
+	case nil:
+		return append(buf, "null"...)
+	case string:
+		return appendJSONString(buf, v)
+	case bool:
+		return strconv.AppendBool(buf, v)
+	case int:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int8:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int16:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int32:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(buf, v, 10)
+	case uint:
+		return strconv.AppendUint(buf, uint64(v), 10)
+	case uint8:
+		return strconv.AppendUint(buf, uint64(v), 10)
+	case uint16:
+		return strconv.AppendUint(buf, uint64(v), 10)
+	case uint32:
+		return strconv.AppendUint(buf, uint64(v), 10)
+	case uint64:
+		return strconv.AppendUint(buf, v, 10)
+	case float32:
+		return appendJSONFloat(buf, float64(v), 32)
+	case float64:
+		return appendJSONFloat(buf, v, 64)
+	case error:
+		return appendJSONString(buf, v.Error())
+	case fmt.Stringer:
+		return appendJSONString(buf, v.String())
+	default:
+		return appendJSONString(buf, fmt.Sprint(v))
+	}
+}
+
+// appendJSONFloat appends f as a JSON number, NaN and infinities are not representable thus quoted.
+func appendJSONFloat(buf []byte, f float64, bitSize int) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		buf = append(buf, '"')
+		buf = strconv.AppendFloat(buf, f, 'g', -1, bitSize)
+		return append(buf, '"')
+	}
+	return strconv.AppendFloat(buf, f, 'g', -1, bitSize)
+}
+
+const hex = "0123456789abcdef"
+
+// appendJSONString appends s to buf as a quoted JSON string.
+// Invalid UTF-8 is replaced with U+FFFD, U+2028 and U+2029 are escaped as encoding/json does.
+func appendJSONString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' {
+				i++
+				continue
+			}
+			buf = append(buf, s[start:i]...)
+			switch b {
+			case '"', '\\':
+				buf = append(buf, '\\', b)
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', hex[b>>4], hex[b&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, '\\', 'u', '2', '0', '2', hex[r&0xf])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
@@ -0,0 +1,94 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestJSONLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewJSONLogger(&buf, Lshortfile)
+	l.With("request_id", 42, "err", errors.New("oops")).Warnf("Test %s", "json")
+
+	exp := `{"level":"WARN","caller":"json_test.go:15","msg":"Test json","request_id":42,"err":"oops"}` + "\n"
+	if buf.String() != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, buf.String())
+	}
+}
+
+func TestJSONLoggerFlags(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewJSONLogger(&buf, LstdFlags|LUTC)
+	l.Println("Test", "flags")
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("Invalid JSON '%s': %v", buf.String(), err)
+	}
+	if _, ok := m["time"]; !ok {
+		t.Errorf("Key 'time' is expected: '%s'", buf.String())
+	}
+	if _, ok := m["caller"]; ok {
+		t.Errorf("Key 'caller' is NOT expected: '%s'", buf.String())
+	}
+	if m["msg"] != "Test flags" {
+		t.Errorf("Expected msg 'Test flags', got '%v'", m["msg"])
+	}
+}
+
+func TestJSONLoggerReservedKeys(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewJSONLogger(&buf, 0)
+	l.With("msg", "x", "level", 1, "time", 2, "user", "u").Info("a")
+
+	exp := `{"level":"INFO","msg":"a","fields.msg":"x","fields.level":1,"fields.time":2,"user":"u"}` + "\n"
+	if buf.String() != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, buf.String())
+	}
+}
+
+func TestAppendJSONString(t *testing.T) {
+	for _, s := range []string{
+		"plain",
+		`quote " and backslash \`,
+		"control \n\r\t\x00\x1f",
+		"unicode 中文 \u2028 \u2029",
+		"invalid \xff utf-8",
+	} {
+		var got string
+		encoded := appendJSONString(nil, s)
+		if err := json.Unmarshal(encoded, &got); err != nil {
+			t.Errorf("Invalid JSON string '%s': %v", encoded, err)
+			continue
+		}
+		exp := strings.ToValidUTF8(s, "\ufffd")
+		if got != exp {
+			t.Errorf("Expected '%s', got '%s'", exp, got)
+		}
+	}
+}
+
+func TestAppendJSONValue(t *testing.T) {
+	cases := []struct {
+		value interface{}
+		exp   string
+	}{
+		{nil, "null"},
+		{true, "true"},
+		{-1, "-1"},
+		{uint8(255), "255"},
+		{1.5, "1.5"},
+		{math.NaN(), `"NaN"`},
+		{math.Inf(1), `"+Inf"`},
+		{[]int{1, 2}, `"[1 2]"`},
+	}
+	for _, c := range cases {
+		if got := string(appendJSONValue(nil, c.value)); got != c.exp {
+			t.Errorf("Expected '%s', got '%s'", c.exp, got)
+		}
+	}
+}
@@ -4,12 +4,14 @@ import "strings"
 
 // Log levels from low to high.
 // NOTE: FATAL is the highest, NOTSET is the lowest.
+// The numeric value of FATA is 6 since PANIC is added, it was 5 before, compare levels by the constants rather than numbers.
 const (
 	NOTSET Level = iota
 	DEBUG
 	INFO
 	WARN
 	ERROR
+	PANIC
 	FATA
 )
 
@@ -25,6 +27,8 @@ func LevelFromString(s string) Level {
 		return WARN
 	case "ERROR", "E":
 		return ERROR
+	case "PANIC", "P":
+		return PANIC
 	case "FATA", "FATAL", "F":
 		return FATA
 	case "NOTSET", "NOT SET", "N":
@@ -48,6 +52,8 @@ func (l Level) String() string {
 		return "WARN"
 	case ERROR:
 		return "ERROR"
+	case PANIC:
+		return "PANIC"
 	case FATA:
 		return "FATA"
 	case NOTSET:
@@ -56,3 +62,18 @@ func (l Level) String() string {
 		return "NOT SET"
 	}
 }
+
+// MarshalText implements encoding.TextMarshaler.
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, text is parsed by ParseLevel.
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
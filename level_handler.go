@@ -0,0 +1,254 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LevelHandler is an http.Handler exposing levels to be changed at runtime.
+//
+// GET responds the OutputLevel and DefaultLevel of the Leveler, and the OutputLevels set on named loggers in JSON:
+//
+//	{"output_level":"INFO","default_level":"INFO","loggers":{"app.storage":"DEBUG"}}
+//
+// or in the form of ParseLevelSpec if the request accepts text/plain: INFO;app.storage=DEBUG
+//
+// PUT changes levels with a JSON body in the same form if its Content-Type is application/json,
+// in which absent levels are left unchanged and a null level makes the named logger inherit again.
+// Otherwise the body is parsed by ParseLevelSpec, and the query parameter default_level sets the DefaultLevel.
+// The levels after change are responded as GET.
+//
+// If the query parameter revert_after of PUT, e.g. "5m", or the default set by SetRevertAfter is positive,
+// the changes are reverted after the duration. A pending revert covers later PUTs as well,
+// which reverts to the levels before the earliest PUT, including the named loggers changed by later PUTs.
+// A later PUT to be reverted reschedules the pending revert after its own duration,
+// while revert_after=0 cancels the pending revert and keeps the levels.
+type LevelHandler struct {
+	leveler     Leveler
+	revertAfter time.Duration
+
+	mu      sync.Mutex
+	pending *levelRevert
+}
+
+// levelRevert is a pending revert of levels.
+type levelRevert struct {
+	timer        *time.Timer
+	outputLevel  Level
+	defaultLevel Level
+	// loggers are the OutputLevels of named loggers before change, levelUnset for inherited.
+	loggers map[string]Level
+}
+
+// levelsBody is the body of requests and responses of LevelHandler.
+type levelsBody struct {
+	OutputLevel  *Level            `json:"output_level,omitempty"`
+	DefaultLevel *Level            `json:"default_level,omitempty"`
+	Loggers      map[string]*Level `json:"loggers,omitempty"`
+}
+
+// NewLevelHandler creates a LevelHandler of leveler, the default logger is used if leveler is nil.
+// Levels of named loggers are always those got by GetLogger.
+func NewLevelHandler(leveler Leveler) *LevelHandler {
+	return &LevelHandler{leveler: leveler}
+}
+
+// SetRevertAfter sets the default duration after which changes are reverted, 0 disables reverting by default.
+func (h *LevelHandler) SetRevertAfter(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.revertAfter = d
+}
+
+// ServeHTTP implements http.Handler.
+func (h *LevelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodPut:
+		body, revertAfter, cancel, err := h.parsePut(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.apply(body, revertAfter, cancel)
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	h.respond(w, r)
+}
+
+func (h *LevelHandler) getLeveler() Leveler {
+	if h.leveler == nil {
+		return DefaultLogger()
+	}
+	return h.leveler
+}
+
+// parsePut parses a PUT request, cancel reports whether revert_after is explicitly not positive.
+func (h *LevelHandler) parsePut(r *http.Request) (body levelsBody, revertAfter time.Duration, cancel bool, err error) {
+	h.mu.Lock()
+	revertAfter = h.revertAfter
+	h.mu.Unlock()
+
+	query := r.URL.Query()
+	if s := query.Get("revert_after"); s != "" {
+		if revertAfter, err = time.ParseDuration(s); err != nil {
+			return body, 0, false, fmt.Errorf("invalid revert_after: %w", err)
+		}
+		cancel = revertAfter <= 0
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		return body, 0, false, err
+	}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		if err = json.Unmarshal(data, &body); err != nil {
+			return body, 0, false, err
+		}
+		if _, ok := body.Loggers[""]; ok {
+			return body, 0, false, fmt.Errorf("empty logger name")
+		}
+		return body, revertAfter, cancel, nil
+	}
+
+	levels, err := ParseLevelSpec(string(data))
+	if err != nil {
+		return body, 0, false, err
+	}
+	body.Loggers = make(map[string]*Level, len(levels))
+	for name, level := range levels {
+		if name == "" {
+			body.OutputLevel = &level
+		} else {
+			body.Loggers[name] = &level
+		}
+	}
+	if s := query.Get("default_level"); s != "" {
+		level, err := ParseLevel(s)
+		if err != nil {
+			return body, 0, false, err
+		}
+		body.DefaultLevel = &level
+	}
+	return body, revertAfter, cancel, nil
+}
+
+// apply applies the levels of body, a pending revert is scheduled or rescheduled if revertAfter is positive,
+// canceled if cancel is true, or kept otherwise.
+func (h *LevelHandler) apply(body levelsBody, revertAfter time.Duration, cancel bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	leveler := h.getLeveler()
+	rv := h.pending
+	switch {
+	case revertAfter > 0:
+		if rv != nil {
+			rv.timer.Stop()
+		} else {
+			rv = &levelRevert{
+				outputLevel:  leveler.OutputLevel(),
+				defaultLevel: leveler.DefaultLevel(),
+				loggers:      make(map[string]Level),
+			}
+		}
+		rv.timer = time.AfterFunc(revertAfter, func() { h.revert(rv) })
+		h.pending = rv
+	case cancel && rv != nil:
+		rv.timer.Stop()
+		h.pending = nil
+		rv = nil
+	}
+	// The pending revert covers the named loggers changed from now on.
+	if rv != nil {
+		for name := range body.Loggers {
+			if _, ok := rv.loggers[name]; !ok {
+				rv.loggers[name] = GetLogger(name).ownOutputLevel()
+			}
+		}
+	}
+
+	if body.OutputLevel != nil {
+		leveler.SetOutputLevel(*body.OutputLevel)
+	}
+	if body.DefaultLevel != nil {
+		leveler.SetDefaultLevel(*body.DefaultLevel)
+	}
+	for name, level := range body.Loggers {
+		setNamedOutputLevel(name, level)
+	}
+}
+
+func (h *LevelHandler) revert(rv *levelRevert) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.pending != rv {
+		return
+	}
+	h.pending = nil
+	leveler := h.getLeveler()
+	leveler.SetOutputLevel(rv.outputLevel)
+	leveler.SetDefaultLevel(rv.defaultLevel)
+	for name, level := range rv.loggers {
+		if level == levelUnset {
+			setNamedOutputLevel(name, nil)
+		} else {
+			setNamedOutputLevel(name, &level)
+		}
+	}
+}
+
+// setNamedOutputLevel sets the OutputLevel of the named logger, or makes it inherited if level is nil.
+func setNamedOutputLevel(name string, level *Level) {
+	l := GetLogger(name)
+	if level == nil {
+		l.ResetOutputLevel()
+	} else {
+		l.SetOutputLevel(*level)
+	}
+}
+
+func (h *LevelHandler) respond(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	leveler := h.getLeveler()
+	outputLevel, defaultLevel := leveler.OutputLevel(), leveler.DefaultLevel()
+	body := levelsBody{
+		OutputLevel:  &outputLevel,
+		DefaultLevel: &defaultLevel,
+		Loggers:      make(map[string]*Level),
+	}
+	for name, l := range namedLoggers() {
+		if level := l.ownOutputLevel(); level != levelUnset {
+			body.Loggers[name] = &level
+		}
+	}
+	h.mu.Unlock()
+
+	if strings.Contains(r.Header.Get("Accept"), "text/plain") {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		names := make([]string, 0, len(body.Loggers))
+		for name := range body.Loggers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		spec := []string{outputLevel.String()}
+		for _, name := range names {
+			spec = append(spec, name+"="+body.Loggers[name].String())
+		}
+		_, _ = io.WriteString(w, strings.Join(spec, ";")+"\n")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
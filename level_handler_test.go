@@ -0,0 +1,109 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func doLevelRequest(t *testing.T, h http.Handler, method, target, contentType, body string) string {
+	t.Helper()
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	if !strings.Contains(target, "json") {
+		r.Header.Set("Accept", "text/plain")
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	data, _ := io.ReadAll(w.Result().Body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("%s %s: unexpected status %d: %s", method, target, w.Code, data)
+	}
+	return string(data)
+}
+
+func TestLevelHandler(t *testing.T) {
+	defer SetDefaultLogger(DefaultLogger())
+	SetDefaultLogger(NewLeveledLoggerWithColor(&bytes.Buffer{}, 0, false))
+	h := NewLevelHandler(nil)
+
+	if got := doLevelRequest(t, h, http.MethodGet, "/", "", ""); got != "NOT SET\n" {
+		t.Errorf("Expected 'NOT SET', got '%s'", got)
+	}
+
+	got := doLevelRequest(t, h, http.MethodPut, "/?default_level=WARN", "text/plain", "INFO;app.storage=DEBUG")
+	if got != "INFO;app.storage=DEBUG\n" {
+		t.Errorf("Expected 'INFO;app.storage=DEBUG', got '%s'", got)
+	}
+	if DefaultLevel() != WARN {
+		t.Errorf("Expected DefaultLevel WARN, got %v", DefaultLevel())
+	}
+
+	got = doLevelRequest(t, h, http.MethodPut, "/?json", "application/json", `{"output_level":"ERROR","loggers":{"app.storage":null}}`)
+	exp := `{"output_level":"ERROR","default_level":"WARN"}` + "\n"
+	if got != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, got)
+	}
+
+	r := httptest.NewRequest(http.MethodPut, "/", strings.NewReader("INFO;app=LOUD"))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for malformed spec, got %d", w.Code)
+	}
+}
+
+func TestLevelHandlerRevert(t *testing.T) {
+	defer SetDefaultLogger(DefaultLogger())
+	SetDefaultLogger(NewLeveledLoggerWithColor(&bytes.Buffer{}, 0, false))
+	SetOutputLevel(INFO)
+	h := NewLevelHandler(nil)
+
+	doLevelRequest(t, h, http.MethodPut, "/?revert_after=1h", "", "DEBUG;app=WARN")
+	doLevelRequest(t, h, http.MethodPut, "/?revert_after=10ms", "", "DEBUG;app.http=ERROR")
+	if OutputLevel() != DEBUG || GetLogger("app.http").OutputLevel() != ERROR {
+		t.Fatalf("Levels are expected to be changed")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for OutputLevel() != INFO && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := doLevelRequest(t, h, http.MethodGet, "/", "", ""); got != "INFO\n" {
+		t.Errorf("Expected levels before the first PUT 'INFO', got '%s'", got)
+	}
+}
+
+func TestLevelHandlerRevertKept(t *testing.T) {
+	defer SetDefaultLogger(DefaultLogger())
+	SetDefaultLogger(NewLeveledLoggerWithColor(&bytes.Buffer{}, 0, false))
+	SetOutputLevel(INFO)
+	h := NewLevelHandler(nil)
+
+	doLevelRequest(t, h, http.MethodPut, "/?revert_after=200ms", "", "DEBUG")
+	doLevelRequest(t, h, http.MethodPut, "/", "", "DEBUG;kept.db=ERROR")
+	if got := doLevelRequest(t, h, http.MethodGet, "/", "", ""); got != "DEBUG;kept.db=ERROR\n" {
+		t.Fatalf("Expected 'DEBUG;kept.db=ERROR', got '%s'", got)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for OutputLevel() != INFO && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := doLevelRequest(t, h, http.MethodGet, "/", "", ""); got != "INFO\n" {
+		t.Errorf("Expected levels before the first PUT 'INFO', got '%s'", got)
+	}
+
+	doLevelRequest(t, h, http.MethodPut, "/?revert_after=10ms", "", "DEBUG")
+	doLevelRequest(t, h, http.MethodPut, "/?revert_after=0", "", "WARN")
+	time.Sleep(50 * time.Millisecond)
+	if OutputLevel() != WARN {
+		t.Errorf("Expected OutputLevel WARN after the revert is canceled, got %v", OutputLevel())
+	}
+}
@@ -3,10 +3,19 @@ package log
 import "testing"
 
 func TestLevelComparison(t *testing.T) {
-	levelsSortedByValueAsc := []Level{NOTSET, DEBUG, INFO, WARN, FATA}
+	levelsSortedByValueAsc := []Level{NOTSET, DEBUG, INFO, WARN, ERROR, PANIC, FATA}
 	for i, level := range levelsSortedByValueAsc[:len(levelsSortedByValueAsc)-1] {
 		if level >= levelsSortedByValueAsc[i+1] {
 			t.Errorf("Expected level %v to be less than %v", level, levelsSortedByValueAsc[i+1])
 		}
 	}
 }
+
+func TestLevelValues(t *testing.T) {
+	// Values are part of the API, FATA was renumbered from 5 to 6 when PANIC was added.
+	for level, exp := range map[Level]int32{NOTSET: 0, DEBUG: 1, INFO: 2, WARN: 3, ERROR: 4, PANIC: 5, FATA: 6} {
+		if int32(level) != exp {
+			t.Errorf("Expected %v to be %d, got %d", level, exp, int32(level))
+		}
+	}
+}
@@ -1,11 +1,12 @@
 package log
 
 import (
+	"context"
 	"fmt"
 	"io"
-	"log"
-	"os"
+	"strings"
 	"sync/atomic"
+	"time"
 )
 
 // NewLeveledLogger creates a LeveledLogger with given output writer and flag.
@@ -17,56 +18,100 @@ func NewLeveledLogger(out io.Writer, flag int) *LeveledLogger {
 
 // NewLeveledLoggerWithColor is NewLeveledLogger with an additional colored parameter indicating if color is forced.
 func NewLeveledLoggerWithColor(out io.Writer, flag int, colored bool) *LeveledLogger {
-	return &LeveledLogger{
-		debug:        log.New(out, tryPaint("D ", ColorBlue, colored), flag),
-		info:         log.New(out, tryPaint("I ", ColorGreen, colored), flag),
-		warn:         log.New(out, tryPaint("W ", ColorYellow, colored), flag),
-		erro:         log.New(out, tryPaint("E ", ColorMegenta, colored), flag),
-		fata:         log.New(out, tryPaint("F ", ColorRed, colored), flag),
-		defaultLevel: INFO,
-		outputLevel:  NOTSET,
-		depth:        3,
-	}
+	return NewLeveledLoggerWithFormatter(out, flag, &TextFormatter{Flag: flag, Colored: colored})
+}
+
+// NewLeveledLoggerWithFormatter creates a LeveledLogger which formats logs with f.
+// The flag argument decides whether time and caller are collected into Entries,
+// it is usually the same flag f is configured with.
+func NewLeveledLoggerWithFormatter(out io.Writer, flag int, f Formatter) *LeveledLogger {
+	return newLeveledLogger(&writerHandler{w: out, formatter: f}, flag)
 }
 
-func tryPaint(str string, color string, colored bool) string {
-	if !colored {
-		return str
+func newLeveledLogger(h handler, flag int) *LeveledLogger {
+	return &LeveledLogger{
+		handler: h,
+		flag:    flag,
+		levels: &levels{
+			defaultLevel: INFO,
+			outputLevel:  NOTSET,
+		},
+		hooks:      &hooks{},
+		exiter:     newExiter(),
+		sampler:    &sampler{},
+		deduper:    &deduper{},
+		depth:      3,
+		stackLevel: ERROR,
+		now:        time.Now,
 	}
-	return paint(str, color)
 }
 
 // LeveledLogger has the ability of logging with different levels.
 type LeveledLogger struct {
-	debug        *log.Logger
-	info         *log.Logger
-	warn         *log.Logger
-	erro         *log.Logger
-	fata         *log.Logger
+	handler    handler
+	flag       int
+	levels     *levels
+	hooks      *hooks
+	exiter     *exiter
+	sampler    *sampler
+	deduper    *deduper
+	depth      int
+	stackLevel Level
+	now        func() time.Time
+	fields     []Field
+	name       string
+}
+
+// levels are shared between a LeveledLogger and its children.
+// Levels of named loggers are inherited from parent unless set.
+type levels struct {
 	outputLevel  Level
 	defaultLevel Level
-	depth        int
+	parent       *levels
+}
+
+// levelUnset marks a level of named loggers as inherited.
+const levelUnset Level = -1
+
+// output returns the OutputLevel of the nearest levels in which it is set.
+func (lv *levels) output() Level {
+	for ; ; lv = lv.parent {
+		level := Level(atomic.LoadInt32((*int32)(&lv.outputLevel)))
+		if level != levelUnset || lv.parent == nil {
+			return level
+		}
+	}
+}
+
+// defaults returns the DefaultLevel of the nearest levels in which it is set.
+func (lv *levels) defaults() Level {
+	for ; ; lv = lv.parent {
+		level := Level(atomic.LoadInt32((*int32)(&lv.defaultLevel)))
+		if level != levelUnset || lv.parent == nil {
+			return level
+		}
+	}
 }
 
 // SetDefaultLevel sets the DefaultLevel atomically.
 func (l *LeveledLogger) SetDefaultLevel(level Level) {
-	atomic.StoreInt32((*int32)(&l.defaultLevel), int32(level))
+	atomic.StoreInt32((*int32)(&l.levels.defaultLevel), int32(level))
 }
 
 // DefaultLevel is the level used by Print* methods.
 func (l *LeveledLogger) DefaultLevel() Level {
-	return Level(atomic.LoadInt32((*int32)(&l.defaultLevel)))
+	return l.levels.defaults()
 }
 
 // SetOutputLevel sets the OutputLevel atomically.
 func (l *LeveledLogger) SetOutputLevel(level Level) {
-	atomic.StoreInt32((*int32)(&l.outputLevel), int32(level))
+	atomic.StoreInt32((*int32)(&l.levels.outputLevel), int32(level))
 }
 
 // OutputLevel returns the minimal Level of log that will be outputted.
 // Levels lower than this will be ignored.
 func (l *LeveledLogger) OutputLevel() Level {
-	return Level(atomic.LoadInt32((*int32)(&l.outputLevel)))
+	return l.levels.output()
 }
 
 // SetCallerOffset sets the offset used in runtime.Caller(3 + offset)
@@ -76,6 +121,71 @@ func (l *LeveledLogger) SetCallerOffset(offset int) {
 	l.depth = offset + 3
 }
 
+// SetClock sets the function returning the current time of logs, which is time.Now by default.
+// It's also the clock of windows of SetSampling and SetDedup.
+// It's useful for deterministic tests, children created afterwards inherit it.
+// NOTE: Do not call this while logging, it's not goroutine safe.
+func (l *LeveledLogger) SetClock(now func() time.Time) {
+	if now == nil {
+		now = time.Now
+	}
+	l.now = now
+}
+
+// With returns a child logger with given key/value pairs bound,
+// which are rendered after the message of every log.
+// Keys are expected to be strings, a Field is also accepted in place of a pair.
+//
+// The child shares output and levels with l, and inherits the caller offset of l.
+func (l *LeveledLogger) With(keyvals ...interface{}) Logger {
+	return l.withFields(fieldsFromKeyvals(keyvals))
+}
+
+// WithFields is With with typed Fields.
+func (l *LeveledLogger) WithFields(fields ...Field) Logger {
+	return l.withFields(fields)
+}
+
+func (l *LeveledLogger) withFields(fields []Field) *LeveledLogger {
+	child := *l
+	child.fields = make([]Field, 0, len(l.fields)+len(fields))
+	child.fields = append(child.fields, l.fields...)
+	child.fields = append(child.fields, fields...)
+	return &child
+}
+
+// AddHook adds a Hook which is fired on each log of the levels it is interested in.
+// Hooks are shared between l and its children.
+func (l *LeveledLogger) AddHook(hook Hook) {
+	l.hooks.add(hook)
+}
+
+// SetAsync makes l write asynchronously, logs are formatted on the calling goroutine
+// then handed to background goroutines through queues of given size, see AsyncWriter.
+// Flush or Close should be called before the program exits, which is done automatically by Fatal* methods.
+// NOTE: Do not call this while logging, it's not goroutine safe.
+func (l *LeveledLogger) SetAsync(size int, policy OverflowPolicy) {
+	l.handler.setAsync(size, policy)
+}
+
+// Flush waits until all logs are written in async mode.
+func (l *LeveledLogger) Flush() error {
+	return l.handler.flush()
+}
+
+// Close logs pending reports of sampling and deduplication, then flushes and stops the background goroutines in async mode,
+// logging after closed is discarded. The underlying writers are not closed.
+func (l *LeveledLogger) Close() error {
+	l.sampler.flush()
+	l.deduper.flush()
+	return l.handler.close()
+}
+
+// Dropped returns the number of logs discarded due to overflow in async mode.
+func (l *LeveledLogger) Dropped() uint64 {
+	return l.handler.dropped()
+}
+
 // Print prints log with DefaultLevel.
 // Arguments are handled in the manner of fmt.Print.
 func (l *LeveledLogger) Print(a ...interface{}) {
@@ -208,13 +318,85 @@ func (l *LeveledLogger) ErrorfDepth(depth int, format string, a ...interface{})
 	l.outputfDepth(depth, ERROR, format, a...)
 }
 
-// Fatal prints log with level FATA then os.Exit(1).
+// DebugCtx prints log with level DEBUG and fields extracted from ctx.
+// Arguments are handled in the manner of fmt.Print.
+func (l *LeveledLogger) DebugCtx(ctx context.Context, a ...interface{}) {
+	l.outputCtx(ctx, DEBUG, a...)
+}
+
+// DebugfCtx prints log with level DEBUG and fields extracted from ctx.
+// Arguments are handled in the manner of fmt.Printf.
+func (l *LeveledLogger) DebugfCtx(ctx context.Context, format string, a ...interface{}) {
+	l.outputfCtx(ctx, DEBUG, format, a...)
+}
+
+// InfoCtx prints log with level INFO and fields extracted from ctx.
+// Arguments are handled in the manner of fmt.Print.
+func (l *LeveledLogger) InfoCtx(ctx context.Context, a ...interface{}) {
+	l.outputCtx(ctx, INFO, a...)
+}
+
+// InfofCtx prints log with level INFO and fields extracted from ctx.
+// Arguments are handled in the manner of fmt.Printf.
+func (l *LeveledLogger) InfofCtx(ctx context.Context, format string, a ...interface{}) {
+	l.outputfCtx(ctx, INFO, format, a...)
+}
+
+// WarnCtx prints log with level WARN and fields extracted from ctx.
+// Arguments are handled in the manner of fmt.Print.
+func (l *LeveledLogger) WarnCtx(ctx context.Context, a ...interface{}) {
+	l.outputCtx(ctx, WARN, a...)
+}
+
+// WarnfCtx prints log with level WARN and fields extracted from ctx.
+// Arguments are handled in the manner of fmt.Printf.
+func (l *LeveledLogger) WarnfCtx(ctx context.Context, format string, a ...interface{}) {
+	l.outputfCtx(ctx, WARN, format, a...)
+}
+
+// ErrorCtx prints log with level ERROR and fields extracted from ctx.
+// Arguments are handled in the manner of fmt.Print.
+func (l *LeveledLogger) ErrorCtx(ctx context.Context, a ...interface{}) {
+	l.outputCtx(ctx, ERROR, a...)
+}
+
+// ErrorfCtx prints log with level ERROR and fields extracted from ctx.
+// Arguments are handled in the manner of fmt.Printf.
+func (l *LeveledLogger) ErrorfCtx(ctx context.Context, format string, a ...interface{}) {
+	l.outputfCtx(ctx, ERROR, format, a...)
+}
+
+// Panic prints log with level PANIC then panics with the message.
+// Arguments are handled in the manner of fmt.Print.
+func (l *LeveledLogger) Panic(a ...interface{}) {
+	l.output(PANIC, a...)
+}
+
+// Panicf prints log with level PANIC then panics with the message.
+// Arguments are handled in the manner of fmt.Printf.
+func (l *LeveledLogger) Panicf(format string, a ...interface{}) {
+	l.outputf(PANIC, format, a...)
+}
+
+// PanicDepth acts as Panic but uses depth to determine which call frame to log
+// PanicDepth(0, "msg") is the same as Panic("msg")
+func (l *LeveledLogger) PanicDepth(depth int, a ...interface{}) {
+	l.outputDepth(depth, PANIC, a...)
+}
+
+// PanicfDepth acts as Panicf but uses depth to determine which call frame to log
+// PanicfDepth(0, "msg") is the same as Panicf("msg")
+func (l *LeveledLogger) PanicfDepth(depth int, format string, a ...interface{}) {
+	l.outputfDepth(depth, PANIC, format, a...)
+}
+
+// Fatal prints log with level FATA then exits, see SetExitFunc.
 // Arguments are handled in the manner of fmt.Print.
 func (l *LeveledLogger) Fatal(a ...interface{}) {
 	l.output(FATA, a...)
 }
 
-// Fatalf prints log with level FATA then os.Exit(1).
+// Fatalf prints log with level FATA then exits, see SetExitFunc.
 // Arguments are handled in the manner of fmt.Printf.
 func (l *LeveledLogger) Fatalf(format string, a ...interface{}) {
 	l.outputf(FATA, format, a...)
@@ -237,17 +419,39 @@ func (l *LeveledLogger) output(level Level, a ...interface{}) {
 }
 
 func (l *LeveledLogger) outputDepth(depth int, level Level, a ...interface{}) {
+	output := level >= l.OutputLevel()
+	if !output && level < PANIC {
+		return
+	}
+
+	msg := fmt.Sprint(a...)
+	if output {
+		l.emit(l.depth+depth, level, msg, msg, a)
+	}
+	l.terminate(level, msg)
+}
+
+func (l *LeveledLogger) outputCtx(ctx context.Context, level Level, a ...interface{}) {
 	if level < l.OutputLevel() {
 		return
 	}
+	l.withContext(ctx).outputDepth(1, level, a...)
+}
 
-	logger := l.getOutputTarget(level)
-	if logger != nil {
-		_ = logger.Output(l.depth+depth, fmt.Sprint(a...))
+func (l *LeveledLogger) outputfCtx(ctx context.Context, level Level, format string, a ...interface{}) {
+	if level < l.OutputLevel() {
+		return
 	}
-	if level == FATA {
-		os.Exit(1)
+	l.withContext(ctx).outputfDepth(1, level, format, a...)
+}
+
+// withContext returns a child with fields extracted from ctx bound, or l itself if there is none.
+func (l *LeveledLogger) withContext(ctx context.Context) *LeveledLogger {
+	fields := fieldsFromContext(ctx)
+	if len(fields) == 0 {
+		return l
 	}
+	return l.withFields(fields)
 }
 
 func (l *LeveledLogger) outputln(level Level, a ...interface{}) {
@@ -255,17 +459,16 @@ func (l *LeveledLogger) outputln(level Level, a ...interface{}) {
 }
 
 func (l *LeveledLogger) outputlnDepth(depth int, level Level, a ...interface{}) {
-	if level < l.OutputLevel() {
+	output := level >= l.OutputLevel()
+	if !output && level < PANIC {
 		return
 	}
 
-	logger := l.getOutputTarget(level)
-	if logger != nil {
-		_ = logger.Output(l.depth+depth, fmt.Sprintln(a...))
-	}
-	if level == FATA {
-		os.Exit(1)
+	msg := fmt.Sprintln(a...)
+	if output {
+		l.emit(l.depth+depth, level, msg, msg, a)
 	}
+	l.terminate(level, msg)
 }
 
 func (l *LeveledLogger) outputf(level Level, format string, a ...interface{}) {
@@ -273,31 +476,86 @@ func (l *LeveledLogger) outputf(level Level, format string, a ...interface{}) {
 }
 
 func (l *LeveledLogger) outputfDepth(depth int, level Level, format string, a ...interface{}) {
-	if level < l.OutputLevel() {
+	output := level >= l.OutputLevel()
+	if !output && level < PANIC {
 		return
 	}
 
-	logger := l.getOutputTarget(level)
-	if logger != nil {
-		_ = logger.Output(l.depth+depth, fmt.Sprintf(format, a...))
+	msg := fmt.Sprintf(format, a...)
+	if output {
+		l.emit(l.depth+depth, level, msg, format, a)
 	}
-	if level == FATA {
-		os.Exit(1)
+	l.terminate(level, msg)
+}
+
+// leveledLogger is implemented by *LeveledLogger and types embedding it, e.g. *logtest.Logger.
+type leveledLogger interface {
+	leveled() *LeveledLogger
+}
+
+func (l *LeveledLogger) leveled() *LeveledLogger {
+	return l
+}
+
+// logf acts as the *f methods of level, except that it never panics or exits on PANIC and FATA.
+func (l *LeveledLogger) logf(level Level, format string, a ...interface{}) {
+	if level < l.OutputLevel() {
+		return
 	}
+	// The caller is one frame shallower than that of the *f methods, which call outputf then outputfDepth.
+	l.emit(l.depth-1, level, fmt.Sprintf(format, a...), format, a)
 }
 
-func (l *LeveledLogger) getOutputTarget(level Level) (logger *log.Logger) {
+// terminate panics on PANIC and exits on FATA, even if the log is not outputted.
+func (l *LeveledLogger) terminate(level Level, msg string) {
 	switch level {
-	case DEBUG:
-		logger = l.debug
-	case INFO:
-		logger = l.info
-	case WARN:
-		logger = l.warn
-	case ERROR:
-		logger = l.erro
+	case PANIC:
+		_ = l.Flush()
+		panic(strings.TrimSuffix(msg, "\n"))
 	case FATA:
-		logger = l.fata
+		l.exiter.exit(l)
+	}
+}
+
+// emit writes msg of given level, calldepth is used to get the caller as in log.Logger.Output.
+// template is the key of logs sampled by template, see SamplingPolicy.
+// Levels out of the range DEBUG to FATA are ignored.
+func (l *LeveledLogger) emit(calldepth int, level Level, msg string, template string, args []interface{}) {
+	if level < DEBUG || level > FATA {
+		return
+	}
+	if policy := l.sampler.policy(level); policy != nil && !l.sampler.sample(l, policy, level, calldepth, template) {
+		return
+	}
+
+	e := entryPool.Get().(*Entry)
+	e.Level = level
+	e.Message = strings.TrimSuffix(msg, "\n")
+	e.Fields = l.fields
+	e.Name = l.name
+	if hasTime(l.flag) {
+		e.Time = l.now()
+	}
+	if hasCaller(l.flag) {
+		e.Caller = callerFrame(calldepth)
+	}
+	if hasStack(l.flag) && level >= l.stackLevel {
+		e.Stack = captureStack(calldepth, args)
+	}
+	l.write(e)
+}
+
+// write fires hooks then handles e unless it's suppressed by deduplication, e is put back to entryPool afterwards.
+func (l *LeveledLogger) write(e *Entry) {
+	if hooks := l.hooks.get(e.Level); len(hooks) > 0 {
+		if e.Time.IsZero() {
+			e.Time = l.now()
+		}
+		fireHooks(hooks, e)
+	}
+	if !l.deduper.suppress(l, e) {
+		l.handler.handle(e)
 	}
-	return
+	*e = Entry{}
+	entryPool.Put(e)
 }
@@ -104,3 +104,44 @@ func TestDynamicCallDepth(t *testing.T) {
 		assertFileAndLine(t, buf.String(), 103)
 	})
 }
+
+func TestWith(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLeveledLoggerWithColor(&buf, 0, false)
+	child := l.With("request_id", 42, "user", "tevino")
+	child.Info("served")
+	child.Infof("served %s", "again")
+	child.WithFields(F("path", "/a b")).Info("nested")
+
+	exp := "I served request_id=42 user=tevino\n" +
+		"I served again request_id=42 user=tevino\n" +
+		"I nested request_id=42 user=tevino path=\"/a b\"\n"
+	if buf.String() != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, buf.String())
+	}
+
+	buf.Reset()
+	l.Info("parent")
+	if buf.String() != "I parent\n" {
+		t.Errorf("Fields of child are NOT expected in parent: '%s'", buf.String())
+	}
+
+	buf.Reset()
+	child.SetOutputLevel(WARN)
+	l.Info("INFO Log")
+	child.Info("INFO Log")
+	if buf.Len() != 0 {
+		t.Errorf("Output level is expected to be shared: '%s'", buf.String())
+	}
+}
+
+func TestWithFileLine(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLeveledLogger(&buf, Lshortfile)
+	l.With("k", "v").Info("Test file line")
+
+	var exp = "leveled_logger_test.go:141"
+	if !strings.Contains(buf.String(), exp) {
+		t.Errorf("Expected filename and line number '%s' not found in: '%s'", exp, buf.String())
+	}
+}
@@ -0,0 +1,99 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LevelEnv is the environment variable read by ConfigureLevelsFromEnv by default.
+const LevelEnv = "LOG_LEVEL"
+
+// LevelSpecError is returned when a level spec is malformed.
+type LevelSpecError struct {
+	// Spec is the whole spec being parsed.
+	Spec string
+	// Item is the malformed item of Spec.
+	Item string
+	// Reason describes what is wrong with Item.
+	Reason string
+}
+
+func (e *LevelSpecError) Error() string {
+	return fmt.Sprintf("log: malformed level spec %q: %s: %q", e.Spec, e.Reason, e.Item)
+}
+
+// ParseLevel parses s to Level as LevelFromString,
+// while a *LevelSpecError is returned instead of NOTSET if s can not be recognized.
+func ParseLevel(s string) (Level, error) {
+	level := LevelFromString(s)
+	if level == NOTSET {
+		switch strings.TrimSpace(strings.ToUpper(s)) {
+		case "NOTSET", "NOT SET", "N":
+		default:
+			return NOTSET, &LevelSpecError{Spec: s, Item: s, Reason: "unknown level"}
+		}
+	}
+	return level, nil
+}
+
+// ParseLevelSpec parses a spec of OutputLevels separated by semicolons, e.g.
+//
+//	INFO;app.storage=DEBUG;app.http=WARN
+//
+// An item without name is for the default logger, which is keyed by an empty name in the result,
+// while the others are for the named loggers got by GetLogger.
+// Later items override earlier ones of the same name, empty items are ignored.
+func ParseLevelSpec(spec string) (map[string]Level, error) {
+	levels := make(map[string]Level)
+	for _, item := range strings.Split(spec, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, levelStr := "", item
+		if i := strings.IndexByte(item, '='); i >= 0 {
+			name, levelStr = strings.TrimSpace(item[:i]), item[i+1:]
+			if name == "" {
+				return nil, &LevelSpecError{Spec: spec, Item: item, Reason: "empty logger name"}
+			}
+		}
+		level, err := ParseLevel(levelStr)
+		if err != nil {
+			return nil, &LevelSpecError{Spec: spec, Item: item, Reason: "unknown level"}
+		}
+		levels[name] = level
+	}
+	return levels, nil
+}
+
+// ConfigureLevels parses spec by ParseLevelSpec then sets OutputLevels of the default logger and named loggers,
+// nothing is changed if spec is malformed.
+func ConfigureLevels(spec string) error {
+	levels, err := ParseLevelSpec(spec)
+	if err != nil {
+		return err
+	}
+	for name, level := range levels {
+		if name == "" {
+			SetOutputLevel(level)
+		} else {
+			GetLogger(name).SetOutputLevel(level)
+		}
+	}
+	return nil
+}
+
+// ConfigureLevelsFromEnv calls ConfigureLevels with the value of the environment variable key,
+// LevelEnv is used if key is empty. Nothing is changed if the variable is not set or empty.
+// It's supposed to be called at startup, after SetDefaultLogger if any.
+func ConfigureLevelsFromEnv(key string) error {
+	if key == "" {
+		key = LevelEnv
+	}
+	spec := os.Getenv(key)
+	if strings.TrimSpace(spec) == "" {
+		return nil
+	}
+	return ConfigureLevels(spec)
+}
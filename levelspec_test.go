@@ -0,0 +1,64 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestParseLevelSpec(t *testing.T) {
+	levels, err := ParseLevelSpec(" INFO; app.storage=debug ;app.http=W;; app.http = ERROR ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := map[string]Level{"": INFO, "app.storage": DEBUG, "app.http": ERROR}
+	if len(levels) != len(exp) {
+		t.Fatalf("Expected %v, got %v", exp, levels)
+	}
+	for name, level := range exp {
+		if levels[name] != level {
+			t.Errorf("Expected %v for '%s', got %v", level, name, levels[name])
+		}
+	}
+}
+
+func TestParseLevelSpecError(t *testing.T) {
+	for spec, item := range map[string]string{
+		"INFO;app=LOUD": "app=LOUD",
+		"=DEBUG":        "=DEBUG",
+		"VERBOSE":       "VERBOSE",
+	} {
+		_, err := ParseLevelSpec(spec)
+		var specErr *LevelSpecError
+		if !errors.As(err, &specErr) {
+			t.Errorf("LevelSpecError is expected for '%s', got %v", spec, err)
+			continue
+		}
+		if specErr.Item != item {
+			t.Errorf("Expected malformed item '%s', got '%s'", item, specErr.Item)
+		}
+	}
+}
+
+func TestConfigureLevelsFromEnv(t *testing.T) {
+	defer SetDefaultLogger(DefaultLogger())
+
+	var buf bytes.Buffer
+	SetDefaultLogger(NewLeveledLoggerWithColor(&buf, 0, false))
+	t.Setenv(LevelEnv, "WARN;app.storage=DEBUG")
+	if err := ConfigureLevelsFromEnv(""); err != nil {
+		t.Fatal(err)
+	}
+
+	Info("filtered")
+	GetLogger("app").Info("filtered")
+	GetLogger("app.storage.cache").Debug("cache")
+	if exp := "D [app.storage.cache] cache\n"; buf.String() != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, buf.String())
+	}
+
+	t.Setenv(LevelEnv, "WARN;app=")
+	if err := ConfigureLevelsFromEnv(""); err == nil {
+		t.Errorf("Error is expected for malformed spec")
+	}
+}
@@ -0,0 +1,54 @@
+package log
+
+import (
+	"io"
+	"strings"
+)
+
+// NewLogfmtLogger creates a LeveledLogger which writes logs in logfmt to out, e.g.
+//
+//	level=info ts="2009/01/23 01:23:23" caller=d.go:23 msg="a message" key=value
+//
+// The flag argument decides which keys are emitted as in NewJSONLogger,
+// keys are always in the order of level, logger, ts, caller, func, msg, followed by bound fields and stack.
+// The key logger is emitted only if the logger is named, stack only if the stack trace is captured.
+func NewLogfmtLogger(out io.Writer, flag int) *LeveledLogger {
+	return NewLeveledLoggerWithFormatter(out, flag, &LogfmtFormatter{Flag: flag})
+}
+
+// LogfmtFormatter formats Entries in logfmt.
+// Flag decides which keys are emitted as in NewLogfmtLogger, TimeLayout is the same as in TextFormatter.
+type LogfmtFormatter struct {
+	Flag       int
+	TimeLayout string
+}
+
+// Format implements Formatter.
+func (f *LogfmtFormatter) Format(buf []byte, e *Entry) []byte {
+	buf = append(buf, "level="...)
+	buf = appendLogfmtString(buf, strings.ToLower(e.Level.String()))
+	if e.Name != "" {
+		buf = append(buf, " logger="...)
+		buf = appendLogfmtString(buf, e.Name)
+	}
+	if hasTime(f.Flag) {
+		buf = append(buf, " ts="...)
+		buf = appendLogfmtString(buf, string(appendTime(nil, e.Time, f.Flag, f.TimeLayout)))
+	}
+	if hasFile(f.Flag) {
+		buf = append(buf, " caller="...)
+		buf = appendLogfmtString(buf, string(appendCaller(nil, e.Caller, f.Flag)))
+	}
+	if f.Flag&Lfunction != 0 {
+		buf = append(buf, " func="...)
+		buf = appendLogfmtString(buf, funcName(e.Caller.Function))
+	}
+	buf = append(buf, " msg="...)
+	buf = appendLogfmtString(buf, e.Message)
+	buf = appendFields(buf, e.Fields)
+	if e.Stack != "" {
+		buf = append(buf, " stack="...)
+		buf = appendLogfmtString(buf, e.Stack)
+	}
+	return append(buf, '\n')
+}
@@ -0,0 +1,31 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLogfmtLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogfmtLogger(&buf, Lshortfile)
+	l.With("request_id", 42, "query", `a="b c"`).Infof("Test %s", "logfmt")
+
+	exp := `level=info caller=logfmt_test.go:12 msg="Test logfmt" request_id=42 query="a=\"b c\""` + "\n"
+	if buf.String() != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, buf.String())
+	}
+}
+
+func TestLogfmtLoggerTime(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogfmtLogger(&buf, Ltime)
+	l.Error("Test")
+
+	if !strings.HasPrefix(buf.String(), "level=error ts=") {
+		t.Errorf("Expected prefix 'level=error ts=' not found in: '%s'", buf.String())
+	}
+	if !strings.HasSuffix(buf.String(), " msg=Test\n") {
+		t.Errorf("Expected suffix ' msg=Test' not found in: '%s'", buf.String())
+	}
+}
@@ -1,5 +1,10 @@
 package log
 
+import (
+	"context"
+	"time"
+)
+
 // PrintLogger represents a logger with Print* APIs.
 type PrintLogger interface {
 	Print(...interface{})
@@ -56,6 +61,27 @@ type FatalLogger interface {
 	FatalfDepth(int, string, ...interface{})
 }
 
+// PanicLogger represents a logger with Panic* APIs.
+type PanicLogger interface {
+	Panic(...interface{})
+	Panicf(string, ...interface{})
+
+	PanicDepth(int, ...interface{})
+	PanicfDepth(int, string, ...interface{})
+}
+
+// ContextLogger represents a logger with *Ctx APIs, which add fields extracted from the context.
+type ContextLogger interface {
+	DebugCtx(context.Context, ...interface{})
+	DebugfCtx(context.Context, string, ...interface{})
+	InfoCtx(context.Context, ...interface{})
+	InfofCtx(context.Context, string, ...interface{})
+	WarnCtx(context.Context, ...interface{})
+	WarnfCtx(context.Context, string, ...interface{})
+	ErrorCtx(context.Context, ...interface{})
+	ErrorfCtx(context.Context, string, ...interface{})
+}
+
 // Leveler contains level-related APIs.
 type Leveler interface {
 	DefaultLevel() Level
@@ -70,6 +96,25 @@ type CallerOffsetter interface {
 	SetCallerOffset(int)
 }
 
+// FieldLogger provides the ability of binding key/value fields.
+type FieldLogger interface {
+	With(...interface{}) Logger
+	WithFields(...Field) Logger
+}
+
+// HookAdder provides the ability of adding hooks.
+type HookAdder interface {
+	AddHook(Hook)
+}
+
+// Exiter provides the ability of customizing the exit on FATA logs.
+type Exiter interface {
+	SetExitFunc(func(code int))
+	SetExitCode(int)
+	SetExitTimeout(time.Duration)
+	RegisterExitHandler(func())
+}
+
 // Logger represents a full-featured logger.
 type Logger interface {
 	DebugLogger
@@ -77,8 +122,13 @@ type Logger interface {
 	InfoLogger
 	WarnLogger
 	ErrorLogger
+	PanicLogger
 	FatalLogger
+	ContextLogger
 
+	FieldLogger
+	HookAdder
+	Exiter
 	Leveler
 	CallerOffsetter
 }
@@ -0,0 +1,145 @@
+// Package logtest provides a Logger recording log entries for tests.
+package logtest
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/tevino/log"
+)
+
+// flag makes time and caller collected for every entry.
+const flag = log.LstdFlags | log.Lshortfile
+
+// Logger is a log.Logger which records log entries, including those of loggers derived by With.
+// OutputLevel is NOTSET by default, thus all the entries are recorded.
+// Fatal* record the exit code rather than exiting the process, see Exited.
+type Logger struct {
+	*log.LeveledLogger
+	rec *recorder
+}
+
+// New creates a Logger which records log entries without outputting them.
+func New() *Logger {
+	return newLogger(io.Discard, &recorder{})
+}
+
+// NewT creates a Logger which records log entries and outputs them through t.Log,
+// so they are attributed to the test and shown only if it fails or runs verbosely.
+// It must not be used after the test finishes.
+func NewT(t testing.TB) *Logger {
+	return newLogger(tWriter{t}, &recorder{formatter: &log.TextFormatter{Flag: log.Lshortfile}})
+}
+
+func newLogger(w io.Writer, rec *recorder) *Logger {
+	l := &Logger{LeveledLogger: log.NewLeveledLoggerWithFormatter(w, flag, rec), rec: rec}
+	l.SetExitFunc(rec.exit)
+	return l
+}
+
+// Entries returns copies of the recorded log entries in the order they are logged.
+func (l *Logger) Entries() []log.Entry {
+	l.rec.mu.Lock()
+	defer l.rec.mu.Unlock()
+
+	return append([]log.Entry(nil), l.rec.entries...)
+}
+
+// Reset discards the recorded log entries and exit.
+func (l *Logger) Reset() {
+	l.rec.mu.Lock()
+	defer l.rec.mu.Unlock()
+
+	l.rec.entries = nil
+	l.rec.exited = false
+	l.rec.code = 0
+}
+
+// Exited returns the exit code recorded by Fatal*, ok is false if there is none.
+func (l *Logger) Exited() (code int, ok bool) {
+	l.rec.mu.Lock()
+	defer l.rec.mu.Unlock()
+
+	return l.rec.code, l.rec.exited
+}
+
+// Logged reports whether an entry of level whose message contains substr is recorded.
+func (l *Logger) Logged(level log.Level, substr string) bool {
+	for _, e := range l.Entries() {
+		if e.Level == level && strings.Contains(e.Message, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// AssertLogged reports an error to t unless an entry of level whose message contains substr is recorded.
+func (l *Logger) AssertLogged(t testing.TB, level log.Level, substr string) {
+	t.Helper()
+	if !l.Logged(level, substr) {
+		t.Errorf("no %v entry containing %q is logged, entries:\n%s", level, substr, l.dump())
+	}
+}
+
+// AssertNotLogged reports an error to t if an entry of level whose message contains substr is recorded.
+func (l *Logger) AssertNotLogged(t testing.TB, level log.Level, substr string) {
+	t.Helper()
+	if l.Logged(level, substr) {
+		t.Errorf("%v entry containing %q is logged, entries:\n%s", level, substr, l.dump())
+	}
+}
+
+func (l *Logger) dump() string {
+	var b strings.Builder
+	for _, e := range l.Entries() {
+		fmt.Fprintf(&b, "\t%v %q", e.Level, e.Message)
+		for _, f := range e.Fields {
+			fmt.Fprintf(&b, " %s=%v", f.Key, f.Value)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// recorder is a log.Formatter recording entries, which are formatted by formatter if it's not nil.
+type recorder struct {
+	formatter log.Formatter
+
+	mu      sync.Mutex
+	entries []log.Entry
+	exited  bool
+	code    int
+}
+
+func (r *recorder) exit(code int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.exited, r.code = true, code
+}
+
+func (r *recorder) Format(buf []byte, e *log.Entry) []byte {
+	entry := *e
+	entry.Fields = append([]log.Field(nil), e.Fields...)
+	r.mu.Lock()
+	r.entries = append(r.entries, entry)
+	r.mu.Unlock()
+
+	if r.formatter == nil {
+		return buf
+	}
+	return r.formatter.Format(buf, e)
+}
+
+// tWriter writes to t.Log.
+type tWriter struct {
+	t testing.TB
+}
+
+func (w tWriter) Write(p []byte) (int, error) {
+	w.t.Log(strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
@@ -0,0 +1,92 @@
+package logtest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/tevino/log"
+)
+
+// fakeT records Errorf and Log calls.
+type fakeT struct {
+	testing.TB
+	errors []string
+	logs   []string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, a ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, a...))
+}
+
+func (t *fakeT) Log(a ...interface{}) {
+	t.logs = append(t.logs, fmt.Sprint(a...))
+}
+
+func TestLogger(t *testing.T) {
+	l := New()
+	l.Info("hello world")
+	l.With("k", "v").Warnf("warn %d", 1)
+
+	entries := l.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	e := entries[1]
+	if e.Level != log.WARN || e.Message != "warn 1" {
+		t.Errorf("Expected WARN 'warn 1', got %v '%s'", e.Level, e.Message)
+	}
+	if len(e.Fields) != 1 || e.Fields[0] != (log.Field{Key: "k", Value: "v"}) {
+		t.Errorf("Expected fields [k=v], got %v", e.Fields)
+	}
+	if !strings.HasSuffix(e.Caller.File, "logtest_test.go") || e.Caller.Line != 31 {
+		t.Errorf("Expected caller logtest_test.go:31, got %s:%d", e.Caller.File, e.Caller.Line)
+	}
+	if e.Time.IsZero() {
+		t.Error("Expected time to be recorded, got zero")
+	}
+
+	l.AssertLogged(t, log.INFO, "world")
+	l.AssertNotLogged(t, log.ERROR, "warn")
+
+	ft := &fakeT{}
+	l.AssertLogged(ft, log.INFO, "warn")
+	l.AssertNotLogged(ft, log.WARN, "warn")
+	if len(ft.errors) != 2 || !strings.Contains(ft.errors[0], `WARN "warn 1" k=v`) {
+		t.Errorf("Expected 2 errors about 'warn 1', got %q", ft.errors)
+	}
+
+	l.Reset()
+	if len(l.Entries()) != 0 {
+		t.Errorf("Expected no entries after Reset, got %d", len(l.Entries()))
+	}
+}
+
+func TestNewT(t *testing.T) {
+	ft := &fakeT{}
+	l := NewT(ft)
+	l.Debug("debug")
+	l.AssertLogged(t, log.DEBUG, "debug")
+	if len(ft.logs) != 1 || ft.logs[0] != "D logtest_test.go:70: debug" {
+		t.Errorf("Expected logs ['D logtest_test.go:70: debug'], got %q", ft.logs)
+	}
+}
+
+func TestExited(t *testing.T) {
+	l := New()
+	if _, ok := l.Exited(); ok {
+		t.Error("Expected no exit, got one")
+	}
+	l.SetExitCode(2)
+	l.Fatal("fatal")
+	l.AssertLogged(t, log.FATA, "fatal")
+	if code, ok := l.Exited(); !ok || code != 2 {
+		t.Errorf("Expected exit with code 2, got code %d and exited %v", code, ok)
+	}
+	l.Reset()
+	if _, ok := l.Exited(); ok {
+		t.Error("Expected no exit after Reset, got one")
+	}
+}
@@ -0,0 +1,115 @@
+package log
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// Minimal MessagePack encoding for the Fluent Forward protocol.
+
+func appendMsgpackArrayHeader(buf []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(buf, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xdc), uint16(n))
+	}
+	return binary.BigEndian.AppendUint32(append(buf, 0xdd), uint32(n))
+}
+
+func appendMsgpackMapHeader(buf []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(buf, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xde), uint16(n))
+	}
+	return binary.BigEndian.AppendUint32(append(buf, 0xdf), uint32(n))
+}
+
+func appendMsgpackString(buf []byte, s string) []byte {
+	switch n := len(s); {
+	case n < 32:
+		buf = append(buf, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		buf = append(buf, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		buf = binary.BigEndian.AppendUint16(append(buf, 0xda), uint16(n))
+	default:
+		buf = binary.BigEndian.AppendUint32(append(buf, 0xdb), uint32(n))
+	}
+	return append(buf, s...)
+}
+
+func appendMsgpackBinaryHeader(buf []byte, n int) []byte {
+	switch {
+	case n <= math.MaxUint8:
+		return append(buf, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xc5), uint16(n))
+	}
+	return binary.BigEndian.AppendUint32(append(buf, 0xc6), uint32(n))
+}
+
+func appendMsgpackInt(buf []byte, i int64) []byte {
+	if i >= 0 {
+		return appendMsgpackUint(buf, uint64(i))
+	}
+	if i >= -32 {
+		return append(buf, byte(i))
+	}
+	return binary.BigEndian.AppendUint64(append(buf, 0xd3), uint64(i))
+}
+
+func appendMsgpackUint(buf []byte, u uint64) []byte {
+	if u < 128 {
+		return append(buf, byte(u))
+	}
+	return binary.BigEndian.AppendUint64(append(buf, 0xcf), u)
+}
+
+// appendMsgpackEventTime appends t as the EventTime extension of the Fluent Forward protocol.
+func appendMsgpackEventTime(buf []byte, t time.Time) []byte {
+	buf = append(buf, 0xd7, 0x00)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(t.Unix()))
+	return binary.BigEndian.AppendUint32(buf, uint32(t.Nanosecond()))
+}
+
+// appendMsgpackValue appends v as a number, bool, nil or string, other types are converted to strings.
+func appendMsgpackValue(buf []byte, v interface{}) []byte {
+	switch v := v.(type) {
+	case nil:
+		return append(buf, 0xc0)
+	case bool:
+		if v {
+			return append(buf, 0xc3)
+		}
+		return append(buf, 0xc2)
+	case int:
+		return appendMsgpackInt(buf, int64(v))
+	case int8:
+		return appendMsgpackInt(buf, int64(v))
+	case int16:
+		return appendMsgpackInt(buf, int64(v))
+	case int32:
+		return appendMsgpackInt(buf, int64(v))
+	case int64:
+		return appendMsgpackInt(buf, v)
+	case uint:
+		return appendMsgpackUint(buf, uint64(v))
+	case uint8:
+		return appendMsgpackUint(buf, uint64(v))
+	case uint16:
+		return appendMsgpackUint(buf, uint64(v))
+	case uint32:
+		return appendMsgpackUint(buf, uint64(v))
+	case uint64:
+		return appendMsgpackUint(buf, v)
+	case float32:
+		return binary.BigEndian.AppendUint32(append(buf, 0xca), math.Float32bits(v))
+	case float64:
+		return binary.BigEndian.AppendUint64(append(buf, 0xcb), math.Float64bits(v))
+	}
+	return appendMsgpackString(buf, fieldString(v))
+}
@@ -0,0 +1,124 @@
+package log
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// named holds the named loggers, whose root is the default logger.
+var named struct {
+	sync.Mutex
+	root    *LeveledLogger
+	loggers map[string]*LeveledLogger
+}
+
+// GetLogger returns the logger of name, which is created along with its ancestors if necessary.
+//
+// Names are dot-separated hierarchies, e.g. the parent of "app.storage.cache" is "app.storage",
+// while the parent of "app" is the default logger. An empty name refers to the default logger itself,
+// with caller offset reset as in With.
+// OutputLevel and DefaultLevel of a named logger are inherited from its parent unless set,
+// which means SetOutputLevel on the default logger applies to all named loggers without their own.
+//
+// Named loggers share output and hooks with the default logger, their names are rendered in logs.
+// If the default logger is not a *LeveledLogger, a LeveledLogger writing to os.Stdout is used as the root instead.
+// NOTE: Named loggers are derived from the default logger at the time they are created,
+// call SetDefaultLogger before GetLogger.
+func GetLogger(name string) *LeveledLogger {
+	named.Lock()
+	defer named.Unlock()
+
+	if named.root == nil {
+		named.root, _ = defaultLogger.(*LeveledLogger)
+		if named.root == nil {
+			named.root = NewLeveledLogger(os.Stdout, LstdFlags|Lshortfile)
+		}
+		// The default logger has caller offset set for package-level functions, which is not wanted here.
+		root := named.root.withFields(nil)
+		root.depth = 3
+		named.loggers = map[string]*LeveledLogger{"": root}
+	}
+	return getLogger(name)
+}
+
+func getLogger(name string) *LeveledLogger {
+	if l, ok := named.loggers[name]; ok {
+		return l
+	}
+
+	parent := named.loggers[""]
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		parent = getLogger(name[:i])
+	}
+	l := &LeveledLogger{
+		handler: parent.handler,
+		flag:    parent.flag,
+		levels: &levels{
+			outputLevel:  levelUnset,
+			defaultLevel: levelUnset,
+			parent:       parent.levels,
+		},
+		hooks:      parent.hooks,
+		exiter:     parent.exiter,
+		sampler:    parent.sampler,
+		deduper:    parent.deduper,
+		depth:      3,
+		stackLevel: parent.stackLevel,
+		now:        parent.now,
+		name:       name,
+	}
+	named.loggers[name] = l
+	return l
+}
+
+// resetNamedLoggers detaches the named loggers from the default logger,
+// which is called when the default logger is replaced.
+func resetNamedLoggers() {
+	named.Lock()
+	defer named.Unlock()
+
+	named.root = nil
+	named.loggers = nil
+}
+
+// Name returns the name of l, which is empty unless l is got by GetLogger.
+func (l *LeveledLogger) Name() string {
+	return l.name
+}
+
+// ResetOutputLevel makes the OutputLevel of a named logger inherited from its parent again.
+// It has no effect on loggers without parent.
+func (l *LeveledLogger) ResetOutputLevel() {
+	if l.levels.parent != nil {
+		l.SetOutputLevel(levelUnset)
+	}
+}
+
+// ResetDefaultLevel makes the DefaultLevel of a named logger inherited from its parent again.
+// It has no effect on loggers without parent.
+func (l *LeveledLogger) ResetDefaultLevel() {
+	if l.levels.parent != nil {
+		l.SetDefaultLevel(levelUnset)
+	}
+}
+
+// namedLoggers returns the named loggers created so far, excluding the default logger.
+func namedLoggers() map[string]*LeveledLogger {
+	named.Lock()
+	defer named.Unlock()
+
+	loggers := make(map[string]*LeveledLogger, len(named.loggers))
+	for name, l := range named.loggers {
+		if name != "" {
+			loggers[name] = l
+		}
+	}
+	return loggers
+}
+
+// ownOutputLevel returns the OutputLevel set on l, which is levelUnset if it's inherited.
+func (l *LeveledLogger) ownOutputLevel() Level {
+	return Level(atomic.LoadInt32((*int32)(&l.levels.outputLevel)))
+}
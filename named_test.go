@@ -0,0 +1,41 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGetLogger(t *testing.T) {
+	defer SetDefaultLogger(DefaultLogger())
+
+	var buf bytes.Buffer
+	root := NewLeveledLoggerWithColor(&buf, Lshortfile, false)
+	SetDefaultLogger(root)
+	root.SetOutputLevel(INFO)
+
+	storage := GetLogger("app.storage")
+	cache := GetLogger("app.storage.cache")
+	http := GetLogger("app.http")
+	if GetLogger("app.storage") != storage {
+		t.Errorf("The same logger is expected for the same name")
+	}
+
+	storage.SetOutputLevel(DEBUG)
+	cache.Debug("cache")
+	http.Debug("http")
+	if exp := "D named_test.go:24: [app.storage.cache] cache\n"; buf.String() != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, buf.String())
+	}
+
+	buf.Reset()
+	storage.ResetOutputLevel()
+	cache.Debug("cache")
+	if buf.Len() != 0 {
+		t.Errorf("OutputLevel INFO is expected to be inherited from the default logger: '%s'", buf.String())
+	}
+
+	GetLogger("").Info("root")
+	if exp := "I named_test.go:37: root\n"; buf.String() != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, buf.String())
+	}
+}
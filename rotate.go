@@ -0,0 +1,353 @@
+package log
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rotation is the interval of time-based rotation.
+type Rotation int
+
+// Rotations.
+const (
+	RotateNever Rotation = iota
+	RotateHourly
+	RotateDaily
+)
+
+// backupTimeFormat is the layout of the time inserted into names of backups.
+// Times of backups are advanced by a millisecond when the names are taken, thus they are unique.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotateOptions are options of RotatingFileWriter, zero values disable the corresponding features.
+type RotateOptions struct {
+	// MaxSize is the maximum size in bytes of the file before it gets rotated.
+	MaxSize int64
+	// MaxAge is the maximum duration to retain backups, judging by the time in their names.
+	MaxAge time.Duration
+	// MaxBackups is the maximum number of backups to retain.
+	MaxBackups int
+	// Compress indicates if backups are compressed with gzip.
+	Compress bool
+	// Rotation is the interval of time-based rotation, the file is rotated on the boundary of every hour or day.
+	Rotation Rotation
+	// UTC indicates if boundaries of Rotation and times in names of backups are in UTC rather than the local time zone,
+	// it should be set if LUTC is used.
+	UTC bool
+}
+
+// RotatingFileWriter is an io.Writer writing to a file which is rotated by size or time.
+// The file is renamed to a backup with the rotation time inserted before its extension,
+// e.g. app.log is renamed to app-2009-01-23T01-23-23.000.log, and a new file is created.
+// Compression and removal of backups are done in background.
+//
+// It's safe for concurrent use.
+type RotatingFileWriter struct {
+	filename string
+	opts     RotateOptions
+	now      func() time.Time
+
+	mu     sync.Mutex
+	file   *os.File // nil if it failed to be reopened, which is retried on the next Write
+	size   int64
+	next   time.Time
+	closed bool
+
+	millMu sync.Mutex
+	millWG sync.WaitGroup
+}
+
+// NewRotatingFileWriter opens or creates the file named filename for appending and returns a RotatingFileWriter of it.
+func NewRotatingFileWriter(filename string, opts RotateOptions) (*RotatingFileWriter, error) {
+	w := &RotatingFileWriter{
+		filename: filename,
+		opts:     opts,
+		now:      time.Now,
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write writes p to the file, which is rotated before writing if MaxSize would be exceeded or Rotation is due.
+// If the file failed to be reopened by an earlier rotation, it's opened again before writing.
+func (w *RotatingFileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+	if w.due(len(p)) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate rotates the file immediately.
+func (w *RotatingFileWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return os.ErrClosed
+	}
+	return w.rotate()
+}
+
+// Reopen closes and reopens the file by its name,
+// it is supposed to be called after the file is moved by an external tool like logrotate, e.g. on SIGHUP.
+func (w *RotatingFileWriter) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return os.ErrClosed
+	}
+	if w.file != nil {
+		if err := w.file.Close(); err != nil {
+			return err
+		}
+		w.file = nil
+	}
+	return w.open()
+}
+
+// Close closes the file and waits for background compression and removal of backups to finish.
+func (w *RotatingFileWriter) Close() error {
+	w.mu.Lock()
+	var err error
+	if w.file != nil {
+		err = w.file.Close()
+		w.file = nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	w.millWG.Wait()
+	return err
+}
+
+func (w *RotatingFileWriter) due(n int) bool {
+	if w.opts.MaxSize > 0 && w.size > 0 && w.size+int64(n) > w.opts.MaxSize {
+		return true
+	}
+	return w.opts.Rotation != RotateNever && !w.currentTime().Before(w.next)
+}
+
+func (w *RotatingFileWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.filename), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(w.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	w.file = f
+	w.size = info.Size()
+	w.next = w.nextBoundary(w.currentTime())
+	return nil
+}
+
+// rotate renames the file to a backup and opens a new one.
+// If the renaming fails, the file is reopened and the rotation is retried on the next Write.
+func (w *RotatingFileWriter) rotate() error {
+	if w.file != nil {
+		if err := w.file.Close(); err != nil {
+			return err
+		}
+		w.file = nil
+	}
+	now := w.currentTime()
+	backup := w.backupName(now)
+	if err := os.Rename(w.filename, backup); err != nil && !os.IsNotExist(err) {
+		next := w.next
+		if w.open() == nil {
+			w.next = next
+		}
+		return err
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+
+	w.millWG.Add(1)
+	go func() {
+		defer w.millWG.Done()
+		w.mill(backup, now)
+	}()
+	return nil
+}
+
+func (w *RotatingFileWriter) location() *time.Location {
+	if w.opts.UTC {
+		return time.UTC
+	}
+	return time.Local
+}
+
+func (w *RotatingFileWriter) currentTime() time.Time {
+	return w.now().In(w.location())
+}
+
+// nextBoundary returns the time of the next time-based rotation after t.
+func (w *RotatingFileWriter) nextBoundary(t time.Time) time.Time {
+	year, month, day := t.Date()
+	switch w.opts.Rotation {
+	case RotateHourly:
+		return time.Date(year, month, day, t.Hour()+1, 0, 0, 0, t.Location())
+	case RotateDaily:
+		return time.Date(year, month, day+1, 0, 0, 0, 0, t.Location())
+	}
+	return time.Time{}
+}
+
+func (w *RotatingFileWriter) splitName() (prefix string, ext string) {
+	ext = filepath.Ext(w.filename)
+	return strings.TrimSuffix(w.filename, ext) + "-", ext
+}
+
+// backupName returns the name of the backup rotated at t, which is not taken by an existing backup.
+func (w *RotatingFileWriter) backupName(t time.Time) string {
+	prefix, ext := w.splitName()
+	for {
+		name := prefix + t.Format(backupTimeFormat) + ext
+		if !exists(name) && !exists(name+".gz") {
+			return name
+		}
+		t = t.Add(time.Millisecond)
+	}
+}
+
+func exists(name string) bool {
+	_, err := os.Lstat(name)
+	return err == nil
+}
+
+// mill compresses the backup if needed and removes the outdated backups as of now.
+// Failures are reported to os.Stderr since there is no caller to return them to.
+func (w *RotatingFileWriter) mill(backup string, now time.Time) {
+	w.millMu.Lock()
+	defer w.millMu.Unlock()
+
+	if w.opts.Compress {
+		if err := compressFile(backup); err != nil {
+			fmt.Fprintf(errorOutput, "log: failed to compress %s: %v\n", backup, err)
+		}
+	}
+	if err := w.removeBackups(now); err != nil {
+		fmt.Fprintf(errorOutput, "log: failed to remove backups of %s: %v\n", w.filename, err)
+	}
+}
+
+type backupFile struct {
+	path string
+	time time.Time
+}
+
+func (w *RotatingFileWriter) backups() ([]backupFile, error) {
+	entries, err := os.ReadDir(filepath.Dir(w.filename))
+	if err != nil {
+		return nil, err
+	}
+	prefix, ext := w.splitName()
+	prefix = filepath.Base(prefix)
+
+	var backups []backupFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		ts := strings.TrimPrefix(name, prefix)
+		ts = strings.TrimSuffix(ts, ".gz")
+		if !strings.HasSuffix(ts, ext) {
+			continue
+		}
+		t, err := time.ParseInLocation(backupTimeFormat, strings.TrimSuffix(ts, ext), w.location())
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backupFile{path: filepath.Join(filepath.Dir(w.filename), name), time: t})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].time.After(backups[j].time)
+	})
+	return backups, nil
+}
+
+func (w *RotatingFileWriter) removeBackups(now time.Time) error {
+	if w.opts.MaxBackups <= 0 && w.opts.MaxAge <= 0 {
+		return nil
+	}
+	backups, err := w.backups()
+	if err != nil {
+		return err
+	}
+	cutoff := now.Add(-w.opts.MaxAge)
+	for i, b := range backups {
+		tooMany := w.opts.MaxBackups > 0 && i >= w.opts.MaxBackups
+		tooOld := w.opts.MaxAge > 0 && b.time.Before(cutoff)
+		if tooMany || tooOld {
+			if err := os.Remove(b.path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// compressFile compresses name into name.gz then removes name.
+func compressFile(name string) (err error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+
+	dst, err := os.OpenFile(name+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = dst.Close()
+			_ = os.Remove(name + ".gz")
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err != nil {
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	_ = src.Close()
+	return os.Remove(name)
+}
@@ -0,0 +1,231 @@
+package log
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func listDir(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func newTestRotatingFileWriter(t *testing.T, opts RotateOptions, now *time.Time) (*RotatingFileWriter, string) {
+	t.Helper()
+	dir := t.TempDir()
+	w, err := NewRotatingFileWriter(filepath.Join(dir, "app.log"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.now = func() time.Time { return *now }
+	w.next = w.nextBoundary(w.currentTime())
+	return w, dir
+}
+
+func TestRotatingFileWriterSize(t *testing.T) {
+	now := time.Date(2009, 1, 23, 1, 23, 23, 0, time.UTC)
+	w, dir := newTestRotatingFileWriter(t, RotateOptions{MaxSize: 10, MaxBackups: 2, UTC: true}, &now)
+
+	for i := 0; i < 4; i++ {
+		if _, err := w.Write([]byte("0123456789")); err != nil {
+			t.Fatal(err)
+		}
+		now = now.Add(time.Second)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []string{"app-2009-01-23T01-23-25.000.log", "app-2009-01-23T01-23-26.000.log", "app.log"}
+	if got := listDir(t, dir); !equalStrings(got, exp) {
+		t.Errorf("Expected files %v, got %v", exp, got)
+	}
+}
+
+func TestRotatingFileWriterTime(t *testing.T) {
+	now := time.Date(2009, 1, 23, 23, 59, 59, 0, time.UTC)
+	w, dir := newTestRotatingFileWriter(t, RotateOptions{Rotation: RotateDaily, Compress: true, UTC: true}, &now)
+
+	if _, err := w.Write([]byte("day 1\n")); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(time.Second)
+	if _, err := w.Write([]byte("day 2\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []string{"app-2009-01-24T00-00-00.000.log.gz", "app.log"}
+	if got := listDir(t, dir); !equalStrings(got, exp) {
+		t.Fatalf("Expected files %v, got %v", exp, got)
+	}
+
+	f, err := os.Open(filepath.Join(dir, exp[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "day 1\n" {
+		t.Errorf("Expected 'day 1' in backup, got '%s'", content)
+	}
+}
+
+func TestRotatingFileWriterReopen(t *testing.T) {
+	now := time.Now()
+	w, dir := newTestRotatingFileWriter(t, RotateOptions{}, &now)
+	defer w.Close()
+
+	if _, err := w.Write([]byte("before\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filepath.Join(dir, "app.log"), filepath.Join(dir, "app.log.1")); err != nil {
+		t.Skip("renaming an opened file is not supported:", err)
+	}
+	if err := w.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("after\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "after\n" {
+		t.Errorf("Expected 'after' in reopened file, got '%s'", content)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRotatingFileWriterMaxAge(t *testing.T) {
+	now := time.Date(2009, 1, 23, 1, 0, 0, 0, time.UTC)
+	w, dir := newTestRotatingFileWriter(t, RotateOptions{MaxAge: 90 * time.Minute, Rotation: RotateHourly, UTC: true}, &now)
+
+	for i := 0; i < 4; i++ {
+		now = now.Add(time.Hour)
+		if _, err := w.Write([]byte("hour\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []string{"app-2009-01-23T04-00-00.000.log", "app-2009-01-23T05-00-00.000.log", "app.log"}
+	if got := listDir(t, dir); !equalStrings(got, exp) {
+		t.Errorf("Expected files %v, got %v", exp, got)
+	}
+}
+
+func TestRotatingFileWriterSameTime(t *testing.T) {
+	now := time.Date(2009, 1, 23, 1, 23, 23, 0, time.UTC)
+	w, dir := newTestRotatingFileWriter(t, RotateOptions{MaxSize: 10, UTC: true}, &now)
+
+	for i := 0; i < 3; i++ {
+		if _, err := w.Write([]byte("0123456789")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []string{"app-2009-01-23T01-23-23.000.log", "app-2009-01-23T01-23-23.001.log", "app.log"}
+	if got := listDir(t, dir); !equalStrings(got, exp) {
+		t.Errorf("Expected files %v, got %v", exp, got)
+	}
+}
+
+func TestRotatingFileWriterRecover(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("directories of open files can not be removed on Windows")
+	}
+	dir := filepath.Join(t.TempDir(), "logs")
+	w, err := NewRotatingFileWriter(filepath.Join(dir, "app.log"), RotateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = w.Close() }()
+
+	// Neither renaming nor reopening works while the directory is replaced by a file.
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dir, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Rotate(); err == nil {
+		t.Error("Expected Rotate to fail")
+	}
+	if _, err := w.Write([]byte("lost\n")); err == nil {
+		t.Error("Expected Write to fail")
+	}
+
+	if err := os.Remove(dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("recovered\n")); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "recovered\n" {
+		t.Errorf("Expected 'recovered\\n', got '%s'", b)
+	}
+}
+
+func TestRotatingFileWriterMillFailure(t *testing.T) {
+	defer func(w io.Writer) { errorOutput = w }(errorOutput)
+	var errs bytes.Buffer
+	errorOutput = &errs
+
+	now := time.Date(2009, 1, 23, 1, 23, 23, 0, time.UTC)
+	w, dir := newTestRotatingFileWriter(t, RotateOptions{Compress: true}, &now)
+	defer func() { _ = w.Close() }()
+
+	w.mill(filepath.Join(dir, "missing.log"), now)
+	if !strings.HasPrefix(errs.String(), "log: failed to compress") {
+		t.Errorf("Expected a compression failure, got '%s'", errs.String())
+	}
+}
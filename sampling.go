@@ -0,0 +1,194 @@
+package log
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SamplingPolicy limits repeated logs of a call site, see LeveledLogger.SetSampling.
+type SamplingPolicy struct {
+	// Interval is the duration of counting windows, which start on the first log of a call site, it must be positive.
+	Interval time.Duration
+	// First is the number of logs of a call site outputted in each window.
+	First int
+	// Thereafter is that every Thereafter-th log is outputted after First logs in each window,
+	// zero suppresses all of them.
+	Thereafter int
+	// ByTemplate indicates if logs are counted by the format of Printf style methods, or the message of others,
+	// rather than the call site.
+	ByTemplate bool
+}
+
+// sampler counts logs by call sites, it's shared between a LeveledLogger and its children.
+// Policies are copied on write so checking them does not require locking.
+type sampler struct {
+	policies atomic.Value // [FATA + 1]*SamplingPolicy
+
+	mu    sync.Mutex
+	sites map[sampleKey]*sampleSite
+	timer *time.Timer
+}
+
+type sampleKey struct {
+	level    Level
+	pc       uintptr
+	template string
+}
+
+type sampleSite struct {
+	logger     *LeveledLogger
+	level      Level
+	policy     *SamplingPolicy
+	frame      runtime.Frame
+	start      time.Time
+	count      int
+	suppressed int
+}
+
+// SetSampling sets the sampling policy of logs of level, nil disables sampling of level.
+//
+// Within each window of policy.Interval, the first policy.First logs of a call site are outputted,
+// then every policy.Thereafter-th of the rest, the others are suppressed.
+// Numbers of suppressed logs are reported at the end of windows in logs of the same level, e.g.
+//
+//	W 2009/01/23 01:23:23 client.go:88: suppressed 4213 messages from client.go:88
+//
+// Pending reports are logged on Close as well. Policies are shared between l and its children.
+// Windows are measured by the clock set by SetClock.
+func (l *LeveledLogger) SetSampling(level Level, policy *SamplingPolicy) {
+	if level < DEBUG || level > FATA {
+		return
+	}
+	l.sampler.mu.Lock()
+	defer l.sampler.mu.Unlock()
+
+	policies, _ := l.sampler.policies.Load().([FATA + 1]*SamplingPolicy)
+	if policy != nil {
+		p := *policy
+		policy = &p
+	}
+	policies[level] = policy
+	l.sampler.policies.Store(policies)
+}
+
+func (s *sampler) policy(level Level) *SamplingPolicy {
+	policies, _ := s.policies.Load().([FATA + 1]*SamplingPolicy)
+	return policies[level]
+}
+
+// sample reports whether the log should be outputted, skip is the same as in callerFrame.
+func (s *sampler) sample(l *LeveledLogger, policy *SamplingPolicy, level Level, skip int, template string) bool {
+	key := sampleKey{level: level}
+	var pcs [1]uintptr
+	runtime.Callers(skip+2, pcs[:])
+	if policy.ByTemplate {
+		key.template = template
+	} else {
+		key.pc = pcs[0]
+	}
+
+	now := l.now()
+	s.mu.Lock()
+	site := s.sites[key]
+	var expired *sampleSite
+	if site != nil && now.Sub(site.start) >= site.policy.Interval {
+		expired, site = site, nil
+	}
+	if site == nil {
+		if s.sites == nil {
+			s.sites = make(map[sampleKey]*sampleSite)
+		}
+		frame, _ := runtime.CallersFrames(pcs[:]).Next()
+		site = &sampleSite{logger: l, level: level, policy: policy, frame: frame, start: now}
+		s.sites[key] = site
+		if s.timer == nil {
+			s.timer = time.AfterFunc(policy.Interval, s.tick)
+		}
+	}
+
+	site.count++
+	ok := site.count <= policy.First ||
+		policy.Thereafter > 0 && (site.count-policy.First)%policy.Thereafter == 0
+	if !ok {
+		site.suppressed++
+	}
+	s.mu.Unlock()
+
+	// Summaries are written without locking since hooks may log.
+	if expired != nil {
+		writeSummaries([]*sampleSite{expired})
+	}
+	return ok
+}
+
+// tick reports and removes the sites whose windows are over, it's rescheduled while there are sites left,
+// so that sites of call sites or templates no longer logged are not kept forever.
+func (s *sampler) tick() {
+	s.mu.Lock()
+	s.timer = nil
+	var next time.Duration
+	var summaries []*sampleSite
+	for key, site := range s.sites {
+		remaining := site.policy.Interval - site.logger.now().Sub(site.start)
+		if remaining <= 0 {
+			summaries = append(summaries, site)
+			delete(s.sites, key)
+		} else if next == 0 || remaining < next {
+			next = remaining
+		}
+	}
+	if next > 0 {
+		s.timer = time.AfterFunc(next, s.tick)
+	}
+	s.mu.Unlock()
+
+	writeSummaries(summaries)
+}
+
+// flush reports and removes all the sites, then stops the timer.
+func (s *sampler) flush() {
+	s.mu.Lock()
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	summaries := make([]*sampleSite, 0, len(s.sites))
+	for key, site := range s.sites {
+		summaries = append(summaries, site)
+		delete(s.sites, key)
+	}
+	s.mu.Unlock()
+
+	writeSummaries(summaries)
+}
+
+func writeSummaries(sites []*sampleSite) {
+	for _, site := range sites {
+		if e := site.summary(); e != nil {
+			site.logger.write(e)
+		}
+	}
+}
+
+// summary returns the Entry reporting the number of suppressed logs of site, or nil if there is none.
+func (site *sampleSite) summary() *Entry {
+	if site.suppressed == 0 {
+		return nil
+	}
+	l := site.logger
+	e := entryPool.Get().(*Entry)
+	e.Level = site.level
+	e.Message = fmt.Sprintf("suppressed %d messages from %s", site.suppressed,
+		appendCaller(nil, site.frame, Lshortfile))
+	e.Name = l.name
+	if hasTime(l.flag) {
+		e.Time = l.now()
+	}
+	if hasCaller(l.flag) {
+		e.Caller = site.frame
+	}
+	return e
+}
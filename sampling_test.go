@@ -0,0 +1,132 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestSampling(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLeveledLoggerWithColor(&buf, Lshortfile, false)
+	l.SetSampling(WARN, &SamplingPolicy{Interval: time.Hour, First: 2, Thereafter: 3})
+
+	for i := 1; i <= 10; i++ {
+		l.Warnf("warn %d", i)
+		l.Infof("info %d", i)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	var warns []string
+	for _, line := range lines {
+		if strings.HasPrefix(line, "W ") {
+			warns = append(warns, line)
+		}
+	}
+	exp := []string{
+		"W sampling_test.go:35: warn 1",
+		"W sampling_test.go:35: warn 2",
+		"W sampling_test.go:35: warn 5",
+		"W sampling_test.go:35: warn 8",
+	}
+	if strings.Join(warns, "\n") != strings.Join(exp, "\n") || len(lines) != 14 {
+		t.Errorf("Unexpected output '%s'", buf.String())
+	}
+
+	buf.Reset()
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if exp := "W sampling_test.go:35: suppressed 6 messages from sampling_test.go:35\n"; buf.String() != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, buf.String())
+	}
+}
+
+func TestSamplingByTemplate(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLeveledLoggerWithColor(&buf, 0, false)
+	l.SetSampling(ERROR, &SamplingPolicy{Interval: time.Hour, First: 1, ByTemplate: true})
+
+	l.Errorf("failed %d", 1)
+	l.Errorf("failed %d", 2)
+	l.Error("other")
+	l.With("k", "v").Errorf("failed %d", 3)
+	if exp := "E failed 1\nE other\n"; buf.String() != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, buf.String())
+	}
+
+	buf.Reset()
+	l.SetSampling(ERROR, nil)
+	l.Errorf("failed %d", 4)
+	if exp := "E failed 4\n"; buf.String() != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, buf.String())
+	}
+}
+
+func TestSamplingReport(t *testing.T) {
+	var buf syncBuffer
+	l := NewLeveledLoggerWithColor(&buf, 0, false)
+	now := time.Unix(1232670203, 0)
+	l.SetClock(func() time.Time { return now })
+	l.SetSampling(DEBUG, &SamplingPolicy{Interval: time.Hour, First: 1})
+
+	for i := 0; i < 3; i++ {
+		l.Debug("debug")
+	}
+	now = now.Add(time.Hour)
+	l.sampler.tick()
+	exp := "D debug\nD suppressed 2 messages from sampling_test.go:93\n"
+	if buf.String() != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, buf.String())
+	}
+
+	for i := 0; i < 3; i++ {
+		if i == 2 {
+			now = now.Add(time.Hour)
+		}
+		l.Debug("debug")
+	}
+	exp += "D debug\nD suppressed 1 messages from sampling_test.go:106\nD debug\n"
+	if buf.String() != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, buf.String())
+	}
+}
+
+func TestSamplingExpire(t *testing.T) {
+	var buf syncBuffer
+	l := NewLeveledLoggerWithColor(&buf, 0, false)
+	now := time.Unix(1232670203, 0)
+	l.SetClock(func() time.Time { return now })
+	l.SetSampling(INFO, &SamplingPolicy{Interval: time.Hour, First: 1, ByTemplate: true})
+
+	for i := 0; i < 100; i++ {
+		l.Info("request ", i)
+	}
+	now = now.Add(time.Hour)
+	l.sampler.tick()
+	if n := len(l.sampler.sites); n != 0 {
+		t.Errorf("Expected idle sites to expire, got %d", n)
+	}
+	if l.sampler.timer != nil {
+		t.Error("Expected no more ticks")
+	}
+}
@@ -0,0 +1,145 @@
+package log
+
+import (
+	"os"
+	"os/signal"
+)
+
+// Reopener is implemented by writers which can be reopened, e.g. RotatingFileWriter.
+type Reopener interface {
+	Reopen() error
+}
+
+// SignalWatcher changes OutputLevel and reopens writers on signals, see WatchSignals.
+type SignalWatcher struct {
+	leveler   Leveler
+	reopeners []Reopener
+	signals   chan os.Signal
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// WatchSignals starts watching signals until Stop is called:
+//
+//   - SIGUSR1 steps the OutputLevel of leveler down, e.g. from INFO to DEBUG, for more verbose logs.
+//   - SIGUSR2 steps the OutputLevel of leveler up, e.g. from INFO to WARN, for less verbose logs.
+//   - SIGHUP reopens the reopeners, e.g. after log files are moved by logrotate.
+//
+// OutputLevel is kept in the range of DEBUG to FATA, changes are logged through leveler if it's a Logger,
+// otherwise through the default logger. The default logger is used if leveler is nil.
+// Signals are not supported on Windows, on which nothing is watched.
+func WatchSignals(leveler Leveler, reopeners ...Reopener) *SignalWatcher {
+	if leveler == nil {
+		leveler = DefaultLogger()
+	}
+	w := &SignalWatcher{
+		leveler:   leveler,
+		reopeners: reopeners,
+		signals:   make(chan os.Signal, 1),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	var watched []os.Signal
+	for _, sig := range []os.Signal{signalMoreVerbose, signalLessVerbose, signalReopen} {
+		if sig != nil {
+			watched = append(watched, sig)
+		}
+	}
+	if len(watched) > 0 {
+		signal.Notify(w.signals, watched...)
+	}
+	go w.run()
+	return w
+}
+
+// Stop stops watching signals, the default behaviors of the signals are restored.
+func (w *SignalWatcher) Stop() {
+	signal.Stop(w.signals)
+	close(w.stop)
+	<-w.done
+}
+
+func (w *SignalWatcher) run() {
+	defer close(w.done)
+	for {
+		select {
+		case sig := <-w.signals:
+			w.handle(sig)
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+func (w *SignalWatcher) handle(sig os.Signal) {
+	switch sig {
+	case signalMoreVerbose:
+		w.stepOutputLevel(-1)
+	case signalLessVerbose:
+		w.stepOutputLevel(1)
+	case signalReopen:
+		for _, r := range w.reopeners {
+			if err := r.Reopen(); err != nil {
+				logAt(w.logger(), ERROR, "log: failed to reopen %T on %v: %v", r, sig, err)
+			}
+		}
+	}
+}
+
+// stepOutputLevel changes the OutputLevel by delta within the range of DEBUG to FATA.
+// The change is logged with the lower of the old and new levels while it's the OutputLevel,
+// i.e. before stepping up and after stepping down, so that it's always outputted.
+func (w *SignalWatcher) stepOutputLevel(delta Level) {
+	old := w.leveler.OutputLevel()
+	level := old
+	if level < DEBUG {
+		level = DEBUG
+	}
+	level += delta
+	if level < DEBUG {
+		level = DEBUG
+	} else if level > FATA {
+		level = FATA
+	}
+	if level == old {
+		return
+	}
+
+	logLevel := level
+	if old < level {
+		logLevel = old
+	}
+	if level > old {
+		logAt(w.logger(), logLevel, "log: OutputLevel changed from %v to %v", old, level)
+		w.leveler.SetOutputLevel(level)
+	} else {
+		w.leveler.SetOutputLevel(level)
+		logAt(w.logger(), logLevel, "log: OutputLevel changed from %v to %v", old, level)
+	}
+}
+
+func (w *SignalWatcher) logger() Logger {
+	if logger, ok := w.leveler.(Logger); ok {
+		return logger
+	}
+	return DefaultLogger()
+}
+
+// logAt logs with level without panicking or exiting.
+// Logs of PANIC and FATA are written as is by LeveledLogger and types embedding it, and as ERROR by other Loggers.
+func logAt(logger Logger, level Level, format string, a ...interface{}) {
+	if l, ok := logger.(leveledLogger); ok && level >= PANIC {
+		l.leveled().logf(level, format, a...)
+		return
+	}
+	switch {
+	case level <= DEBUG:
+		logger.Debugf(format, a...)
+	case level == INFO:
+		logger.Infof(format, a...)
+	case level == WARN:
+		logger.Warnf(format, a...)
+	default:
+		logger.Errorf(format, a...)
+	}
+}
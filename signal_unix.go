@@ -0,0 +1,16 @@
+//go:build linux || darwin || freebsd || openbsd || netbsd || dragonfly
+// +build linux darwin freebsd openbsd netbsd dragonfly
+
+package log
+
+import (
+	"os"
+	"syscall"
+)
+
+// Signals watched by SignalWatcher.
+var (
+	signalMoreVerbose os.Signal = syscall.SIGUSR1
+	signalLessVerbose os.Signal = syscall.SIGUSR2
+	signalReopen      os.Signal = syscall.SIGHUP
+)
@@ -0,0 +1,133 @@
+//go:build linux || darwin || freebsd || openbsd || netbsd || dragonfly
+// +build linux darwin freebsd openbsd netbsd dragonfly
+
+package log
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// chanWriter sends every write to a channel.
+type chanWriter chan string
+
+func (w chanWriter) Write(p []byte) (int, error) {
+	w <- string(p)
+	return len(p), nil
+}
+
+type testReopener chan struct{}
+
+func (r testReopener) Reopen() error {
+	r <- struct{}{}
+	return errors.New("reopen failed")
+}
+
+func receive(t *testing.T, ch interface{}) string {
+	t.Helper()
+	timeout := time.After(time.Second)
+	switch ch := ch.(type) {
+	case chanWriter:
+		select {
+		case s := <-ch:
+			return s
+		case <-timeout:
+		}
+	case testReopener:
+		select {
+		case <-ch:
+			return ""
+		case <-timeout:
+		}
+	}
+	t.Fatal("timed out")
+	return ""
+}
+
+func TestWatchSignals(t *testing.T) {
+	out := make(chanWriter, 8)
+	l := NewLeveledLoggerWithColor(out, 0, false)
+	l.SetOutputLevel(INFO)
+	reopener := make(testReopener, 1)
+	w := WatchSignals(l, reopener)
+	defer w.Stop()
+
+	kill := func(sig syscall.Signal) {
+		if err := syscall.Kill(os.Getpid(), sig); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	kill(syscall.SIGUSR1)
+	if exp, got := "D log: OutputLevel changed from INFO to DEBUG\n", receive(t, out); got != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, got)
+	}
+	if l.OutputLevel() != DEBUG {
+		t.Errorf("Expected '%s', got '%s'", DEBUG, l.OutputLevel())
+	}
+
+	kill(syscall.SIGUSR2)
+	if exp, got := "D log: OutputLevel changed from DEBUG to INFO\n", receive(t, out); got != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, got)
+	}
+	kill(syscall.SIGUSR2)
+	if exp, got := "I log: OutputLevel changed from INFO to WARN\n", receive(t, out); got != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, got)
+	}
+
+	kill(syscall.SIGHUP)
+	receive(t, reopener)
+	if got := receive(t, out); !strings.HasPrefix(got, "E log: failed to reopen") || !strings.Contains(got, "reopen failed") {
+		t.Errorf("Unexpected log '%s'", got)
+	}
+}
+
+func TestSignalWatcherClamp(t *testing.T) {
+	out := make(chanWriter, 8)
+	l := NewLeveledLoggerWithColor(out, 0, false)
+	w := &SignalWatcher{leveler: l}
+
+	l.SetOutputLevel(FATA)
+	w.handle(syscall.SIGUSR2)
+	if l.OutputLevel() != FATA {
+		t.Errorf("Expected '%s', got '%s'", FATA, l.OutputLevel())
+	}
+
+	l.SetOutputLevel(NOTSET)
+	w.handle(syscall.SIGUSR1)
+	if l.OutputLevel() != DEBUG {
+		t.Errorf("Expected '%s', got '%s'", DEBUG, l.OutputLevel())
+	}
+	w.handle(syscall.SIGUSR1)
+	if l.OutputLevel() != DEBUG {
+		t.Errorf("Expected '%s', got '%s'", DEBUG, l.OutputLevel())
+	}
+	if exp, got := "D log: OutputLevel changed from NOT SET to DEBUG\n", receive(t, out); got != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, got)
+	}
+	if len(out) != 0 {
+		t.Errorf("Expected no more logs, got %d", len(out))
+	}
+}
+
+// wrappedLogger embeds *LeveledLogger as logtest.Logger does.
+type wrappedLogger struct {
+	*LeveledLogger
+}
+
+func TestSignalWatcherWrapped(t *testing.T) {
+	out := make(chanWriter, 8)
+	l := wrappedLogger{NewLeveledLoggerWithColor(out, Lshortfile, false)}
+	w := &SignalWatcher{leveler: l}
+
+	l.SetOutputLevel(PANIC)
+	w.handle(syscall.SIGUSR2)
+	got := receive(t, out)
+	if !strings.HasPrefix(got, "P signal.go:") || !strings.HasSuffix(got, ": log: OutputLevel changed from PANIC to FATA\n") {
+		t.Errorf("Expected the change logged at PANIC by signal.go, got '%s'", got)
+	}
+}
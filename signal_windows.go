@@ -0,0 +1,13 @@
+//go:build windows
+// +build windows
+
+package log
+
+import "os"
+
+// Signals watched by SignalWatcher, none is supported on Windows.
+var (
+	signalMoreVerbose os.Signal
+	signalLessVerbose os.Signal
+	signalReopen      os.Signal
+)
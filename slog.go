@@ -0,0 +1,173 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"strings"
+)
+
+// slog.Levels mapped to and from PANIC and FATA.
+const (
+	SlogLevelPanic = slog.LevelError + 2
+	SlogLevelFatal = slog.LevelError + 4
+)
+
+// LevelFromSlog maps a slog.Level onto Level.
+// Levels in between are mapped onto the lower one, e.g. slog.LevelInfo+2 is mapped onto INFO.
+func LevelFromSlog(level slog.Level) Level {
+	switch {
+	case level < slog.LevelInfo:
+		return DEBUG
+	case level < slog.LevelWarn:
+		return INFO
+	case level < slog.LevelError:
+		return WARN
+	case level < SlogLevelPanic:
+		return ERROR
+	case level < SlogLevelFatal:
+		return PANIC
+	default:
+		return FATA
+	}
+}
+
+// SlogLevel maps a Level onto slog.Level.
+func SlogLevel(level Level) slog.Level {
+	switch level {
+	case DEBUG:
+		return slog.LevelDebug
+	case INFO:
+		return slog.LevelInfo
+	case WARN:
+		return slog.LevelWarn
+	case ERROR:
+		return slog.LevelError
+	case PANIC:
+		return SlogLevelPanic
+	case FATA:
+		return SlogLevelFatal
+	}
+	return slog.LevelDebug - 4
+}
+
+// SlogHandler is a slog.Handler which writes through a LeveledLogger,
+// thus logs of slog and the LeveledLogger are identical.
+//
+// Attributes are rendered as fields, keys of groups are joined by dots, e.g. "group.key".
+type SlogHandler struct {
+	logger *LeveledLogger
+	prefix string
+}
+
+// NewSlogHandler creates a SlogHandler which writes through l.
+func NewSlogHandler(l *LeveledLogger) *SlogHandler {
+	return &SlogHandler{logger: l}
+}
+
+// Enabled reports whether level is not lower than the OutputLevel of the LeveledLogger.
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return LevelFromSlog(level) >= h.logger.OutputLevel()
+}
+
+// Handle writes r through the LeveledLogger, the caller is taken from r.PC.
+// Fields extracted from ctx by the registered ContextExtractors are added as well.
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	l := h.logger.withContext(ctx)
+	e := entryPool.Get().(*Entry)
+	e.Time = r.Time
+	e.Level = LevelFromSlog(r.Level)
+	e.Message = strings.TrimSuffix(r.Message, "\n")
+	e.Fields = l.fields
+	if r.NumAttrs() > 0 {
+		fields := make([]Field, len(l.fields), len(l.fields)+r.NumAttrs())
+		copy(fields, l.fields)
+		r.Attrs(func(a slog.Attr) bool {
+			fields = appendSlogAttr(fields, h.prefix, a)
+			return true
+		})
+		e.Fields = fields
+	}
+	if hasCaller(l.flag) && r.PC != 0 {
+		e.Caller, _ = runtime.CallersFrames([]uintptr{r.PC}).Next()
+	}
+	l.write(e)
+	return nil
+}
+
+// WithAttrs returns a SlogHandler whose LeveledLogger has attrs bound as fields.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make([]Field, 0, len(attrs))
+	for _, a := range attrs {
+		fields = appendSlogAttr(fields, h.prefix, a)
+	}
+	return &SlogHandler{logger: h.logger.withFields(fields), prefix: h.prefix}
+}
+
+// WithGroup returns a SlogHandler which prefixes keys of attributes with name.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &SlogHandler{logger: h.logger, prefix: h.prefix + name + "."}
+}
+
+// appendSlogAttr appends a to fields, attributes of groups are flattened with keys prefixed.
+func appendSlogAttr(fields []Field, prefix string, a slog.Attr) []Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			fields = appendSlogAttr(fields, prefix, ga)
+		}
+		return fields
+	}
+	return append(fields, Field{Key: prefix + a.Key, Value: a.Value.Any()})
+}
+
+// NewSlogLogger creates a LeveledLogger which writes through the handler of logger,
+// levels are mapped by SlogLevel and bound fields are passed as attributes.
+// Time and caller are always collected, it's up to the handler whether to output them.
+func NewSlogLogger(logger *slog.Logger) *LeveledLogger {
+	return newLeveledLogger(slogWriter{h: logger.Handler()}, LstdFlags|Llongfile)
+}
+
+// slogWriter handles Entries by passing them to h as slog.Records.
+type slogWriter struct {
+	h slog.Handler
+}
+
+func (w slogWriter) handle(e *Entry) {
+	ctx := context.Background()
+	level := SlogLevel(e.Level)
+	if !w.h.Enabled(ctx, level) {
+		return
+	}
+	var pc uintptr
+	if e.Caller.PC != 0 {
+		// Frame.PC is the PC of the call instruction while slog expects the return PC.
+		pc = e.Caller.PC + 1
+	}
+	r := slog.NewRecord(e.Time, level, e.Message, pc)
+	if e.Name != "" {
+		r.AddAttrs(slog.String("logger", e.Name))
+	}
+	for _, f := range e.Fields {
+		r.AddAttrs(slog.Any(f.Key, f.Value))
+	}
+	if e.Stack != "" {
+		r.AddAttrs(slog.String("stack", e.Stack))
+	}
+	_ = w.h.Handle(ctx, r)
+}
+
+// slog handlers write synchronously on their own.
+func (w slogWriter) setAsync(int, OverflowPolicy) {}
+func (w slogWriter) flush() error                 { return nil }
+func (w slogWriter) close() error                 { return nil }
+func (w slogWriter) dropped() uint64              { return 0 }
@@ -0,0 +1,71 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSlogHandler(t *testing.T) {
+	var ours, theirs bytes.Buffer
+	l := NewLeveledLoggerWithColor(&ours, 0, false)
+	l.With("k", 1, "g.a", "b").Warn("msg")
+
+	sl := slog.New(NewSlogHandler(NewLeveledLoggerWithColor(&theirs, 0, false)))
+	sl.With("k", 1).WithGroup("g").Warn("msg", "a", "b")
+
+	if ours.String() != theirs.String() {
+		t.Errorf("Expected '%s', got '%s'", ours.String(), theirs.String())
+	}
+}
+
+func TestSlogHandlerLevel(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLeveledLoggerWithColor(&buf, Lshortfile, false)
+	l.SetOutputLevel(INFO)
+	sl := slog.New(NewSlogHandler(l))
+	sl.Debug("filtered")
+	sl.Log(context.Background(), SlogLevelFatal, "fatal")
+
+	var exp = "F slog_test.go:30: fatal\n"
+	if buf.String() != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, buf.String())
+	}
+}
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	sl := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		AddSource: true,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			if a.Key == slog.SourceKey {
+				source := a.Value.Any().(*slog.Source)
+				source.File = source.File[strings.LastIndexByte(source.File, '/')+1:]
+			}
+			return a
+		},
+	}))
+	l := NewSlogLogger(sl)
+	l.With("k", 1).Errorf("msg %d", 2)
+
+	var exp = "level=ERROR source=slog_test.go:54 msg=\"msg 2\" k=1\n"
+	if buf.String() != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, buf.String())
+	}
+}
+
+func TestLevelFromSlog(t *testing.T) {
+	for _, level := range []Level{DEBUG, INFO, WARN, ERROR, PANIC, FATA} {
+		if got := LevelFromSlog(SlogLevel(level)); got != level {
+			t.Errorf("Expected %v, got %v", level, got)
+		}
+	}
+	if got := LevelFromSlog(slog.LevelInfo + 2); got != INFO {
+		t.Errorf("Expected %v, got %v", INFO, got)
+	}
+}
@@ -0,0 +1,97 @@
+package log
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+)
+
+// maxStackDepth is the maximum number of frames captured by Lstack.
+const maxStackDepth = 64
+
+// SetStackLevel sets the lowest level of logs with stack traces if Lstack is set, which is ERROR by default.
+// NOTE: Do not call this while logging, it's not goroutine safe.
+func (l *LeveledLogger) SetStackLevel(level Level) {
+	l.stackLevel = level
+}
+
+// captureStack returns the stack trace of an Entry, skip is the same as in callerFrame.
+//
+// If there is an error in args, the messages of its chain are rendered each on a line,
+// followed by the stack trace of the innermost error with a pkg/errors style StackTrace method,
+// or the stack trace of the caller if there is none, e.g.
+//
+//	error: open config: no such file or directory
+//	caused by: no such file or directory
+//	main.loadConfig
+//		/a/b/c/d.go:23
+//	main.main
+//		/a/b/c/main.go:10
+func captureStack(skip int, args []interface{}) string {
+	var buf []byte
+	var pcs []uintptr
+	for _, arg := range args {
+		err, ok := arg.(error)
+		if !ok || err == nil {
+			continue
+		}
+		prefix, last := "error: ", ""
+		for ; err != nil; err = errors.Unwrap(err) {
+			// Errors adding only stack traces repeat the messages of the errors they wrap.
+			if msg := err.Error(); msg != last {
+				buf = append(buf, prefix...)
+				buf = append(buf, msg...)
+				buf = append(buf, '\n')
+				prefix, last = "caused by: ", msg
+			}
+			if trace := stackTraceOf(err); trace != nil {
+				pcs = trace
+			}
+		}
+		break
+	}
+	if pcs == nil {
+		pcs = make([]uintptr, maxStackDepth)
+		pcs = pcs[:runtime.Callers(skip+2, pcs)]
+	}
+	buf = appendFrames(buf, pcs)
+	if len(buf) == 0 {
+		return ""
+	}
+	return string(buf[:len(buf)-1])
+}
+
+// stackTraceOf returns the program counters of err if it has a StackTrace method as in github.com/pkg/errors,
+// whose result is a slice of uintptr-based frames.
+func stackTraceOf(err error) []uintptr {
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return nil
+	}
+	if t := m.Type().Out(0); t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+	trace := m.Call(nil)[0]
+	pcs := make([]uintptr, trace.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(trace.Index(i).Uint())
+	}
+	return pcs
+}
+
+// appendFrames appends the frames of pcs to buf, each of which is rendered as "function\n\tfile:line\n".
+func appendFrames(buf []byte, pcs []uintptr) []byte {
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		buf = append(buf, frame.Function...)
+		buf = append(buf, "\n\t"...)
+		buf = append(buf, frame.File...)
+		buf = append(buf, ':')
+		buf = itoa(buf, frame.Line, -1)
+		buf = append(buf, '\n')
+		if !more {
+			return buf
+		}
+	}
+}
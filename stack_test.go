@@ -0,0 +1,94 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+type testFrame uintptr
+
+type testStackTrace []testFrame
+
+// stackError has a StackTrace method as errors created by github.com/pkg/errors.
+type stackError struct {
+	msg string
+	pcs []uintptr
+}
+
+func newStackError(msg string) error {
+	pcs := make([]uintptr, 32)
+	return &stackError{msg: msg, pcs: pcs[:runtime.Callers(1, pcs)]}
+}
+
+func (e *stackError) Error() string { return e.msg }
+
+func (e *stackError) StackTrace() testStackTrace {
+	trace := make(testStackTrace, len(e.pcs))
+	for i, pc := range e.pcs {
+		trace[i] = testFrame(pc)
+	}
+	return trace
+}
+
+func TestStack(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLeveledLoggerWithColor(&buf, Lshortfile|Lstack, false)
+
+	l.Info("no stack")
+	if exp := "I stack_test.go:42: no stack\n"; buf.String() != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, buf.String())
+	}
+
+	buf.Reset()
+	l.Error("stack")
+	lines := strings.Split(buf.String(), "\n")
+	if len(lines) < 4 || lines[0] != "E stack_test.go:48: stack" {
+		t.Fatalf("Unexpected output '%s'", buf.String())
+	}
+	if lines[1] != "\tgithub.com/tevino/log.TestStack" || !strings.HasSuffix(lines[2], "/stack_test.go:48") {
+		t.Errorf("Expected stack trace of TestStack, got '%s'", buf.String())
+	}
+
+	buf.Reset()
+	l.SetStackLevel(WARN)
+	l.Warnf("open: %v", fmt.Errorf("open config: %w", os.ErrNotExist))
+	lines = strings.Split(buf.String(), "\n")
+	if len(lines) < 5 {
+		t.Fatalf("Unexpected output '%s'", buf.String())
+	}
+	if exp := []string{
+		"W stack_test.go:59: open: open config: file does not exist",
+		"\terror: open config: file does not exist",
+		"\tcaused by: file does not exist",
+		"\tgithub.com/tevino/log.TestStack",
+	}; strings.Join(lines[:4], "\n") != strings.Join(exp, "\n") {
+		t.Errorf("Expected '%s', got '%s'", strings.Join(exp, "\n"), buf.String())
+	}
+}
+
+func TestStackTraceOfError(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewJSONLogger(&buf, Lstack)
+	err := newStackError("boom")
+	l.With("k", "v").Error(fmt.Errorf("wrapped: %w", err))
+
+	var m map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("Invalid JSON '%s': %v", buf.String(), err)
+	}
+	lines := strings.Split(m["stack"], "\n")
+	if len(lines) < 4 {
+		t.Fatalf("Unexpected stack '%s'", m["stack"])
+	}
+	if exp := "error: wrapped: boom\ncaused by: boom\ngithub.com/tevino/log.newStackError"; strings.Join(lines[:3], "\n") != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, m["stack"])
+	}
+	if !strings.HasSuffix(lines[3], "/stack_test.go:25") {
+		t.Errorf("Expected the stack trace of the error, got '%s'", m["stack"])
+	}
+}
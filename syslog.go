@@ -0,0 +1,317 @@
+package log
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SyslogFacility is the facility of syslog messages.
+type SyslogFacility int
+
+// Syslog facilities.
+const (
+	FacilityKern SyslogFacility = iota
+	FacilityUser
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+	FacilityLPR
+	FacilityNews
+	FacilityUUCP
+	FacilityCron
+	FacilityAuthPriv
+	FacilityFTP
+)
+
+// Local syslog facilities.
+const (
+	FacilityLocal0 SyslogFacility = iota + 16
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+// SyslogFormat is the format of syslog messages.
+type SyslogFormat int
+
+// Syslog formats.
+const (
+	// RFC5424 is the format of RFC 5424, e.g.
+	//	<14>1 2009-01-23T01:23:23.123456+08:00 host app 42 - - d.go:23: a message key=value
+	RFC5424 SyslogFormat = iota
+	// RFC3164 is the BSD format of RFC 3164, e.g.
+	//	<14>Jan 23 01:23:23 host app[42]: d.go:23: a message key=value
+	RFC3164
+)
+
+// SyslogSeverity maps a Level onto the syslog severity, e.g. 3 (err) for ERROR.
+// PANIC and FATA are mapped onto 2 (crit), levels out of range are mapped onto 7 (debug).
+func SyslogSeverity(level Level) int {
+	switch level {
+	case INFO:
+		return 6
+	case WARN:
+		return 4
+	case ERROR:
+		return 3
+	case PANIC, FATA:
+		return 2
+	}
+	return 7
+}
+
+var pid = os.Getpid()
+
+// SyslogFormatter formats Entries as syslog messages, see SyslogWriter.
+//
+// The message consists of the caller, the name of the logger, the message and fields as in TextFormatter,
+// followed by the stack trace if there is one. Flag decides whether the caller is rendered,
+// times are always rendered in the header, in UTC if LUTC is set.
+type SyslogFormatter struct {
+	Flag int
+	// RFC is the format of messages, which is RFC5424 by default.
+	RFC SyslogFormat
+	// Facility is the facility of messages, FacilityKern is replaced by FacilityUser since it's reserved for the kernel.
+	Facility SyslogFacility
+	// Hostname is the host name in the header, which is omitted in RFC3164 and "-" in RFC5424 if empty.
+	// It's usually empty for local syslog daemons, which fill in the host name themselves.
+	Hostname string
+	// AppName is the application name in RFC5424 or the tag in RFC3164, which is the base name of the program if empty.
+	AppName string
+}
+
+// Format implements Formatter.
+func (f *SyslogFormatter) Format(buf []byte, e *Entry) []byte {
+	facility := f.Facility
+	if facility == FacilityKern {
+		facility = FacilityUser
+	}
+	appName := f.AppName
+	if appName == "" {
+		appName = filepath.Base(os.Args[0])
+	}
+	t := e.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	if f.Flag&LUTC != 0 {
+		t = t.UTC()
+	}
+
+	buf = append(buf, '<')
+	buf = strconv.AppendInt(buf, int64(facility)*8+int64(SyslogSeverity(e.Level)), 10)
+	buf = append(buf, '>')
+	if f.RFC == RFC3164 {
+		buf = t.AppendFormat(buf, time.Stamp)
+		if f.Hostname != "" {
+			buf = append(buf, ' ')
+			buf = append(buf, f.Hostname...)
+		}
+		buf = append(buf, ' ')
+		buf = append(buf, appName...)
+		buf = append(buf, '[')
+		buf = strconv.AppendInt(buf, int64(pid), 10)
+		buf = append(buf, "]: "...)
+	} else {
+		buf = append(buf, "1 "...)
+		buf = t.AppendFormat(buf, "2006-01-02T15:04:05.000000Z07:00")
+		buf = append(buf, ' ')
+		buf = appendSyslogHeaderField(buf, f.Hostname)
+		buf = append(buf, ' ')
+		buf = appendSyslogHeaderField(buf, appName)
+		buf = append(buf, ' ')
+		buf = strconv.AppendInt(buf, int64(pid), 10)
+		// Neither MSGID nor STRUCTURED-DATA is used.
+		buf = append(buf, " - - "...)
+	}
+
+	if hasFile(f.Flag) {
+		buf = appendCaller(buf, e.Caller, f.Flag)
+		if f.Flag&Lfunction != 0 {
+			buf = append(buf, ' ')
+		}
+	}
+	if f.Flag&Lfunction != 0 {
+		buf = append(buf, funcName(e.Caller.Function)...)
+	}
+	if hasCaller(f.Flag) {
+		buf = append(buf, ": "...)
+	}
+	if e.Name != "" {
+		buf = append(buf, '[')
+		buf = append(buf, e.Name...)
+		buf = append(buf, "] "...)
+	}
+	buf = append(buf, e.Message...)
+	buf = appendFields(buf, e.Fields)
+	if e.Stack != "" {
+		buf = append(buf, '\n')
+		buf = append(buf, e.Stack...)
+	}
+	return buf
+}
+
+// appendSyslogHeaderField appends s to buf with spaces replaced, or "-" if s is empty.
+func appendSyslogHeaderField(buf []byte, s string) []byte {
+	if s == "" {
+		return append(buf, '-')
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c > ' ' && c < 0x7f {
+			buf = append(buf, c)
+		} else {
+			buf = append(buf, '_')
+		}
+	}
+	return buf
+}
+
+// dialTimeout is the timeout of connecting to log daemons and servers.
+const dialTimeout = 5 * time.Second
+
+// localSyslogPaths are the paths of the local syslog daemon to try.
+var localSyslogPaths = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// SyslogWriter is an io.Writer which writes each Write as a syslog message to a syslog daemon,
+// it's usually used with SyslogFormatter, e.g.
+//
+//	w, err := log.NewSyslogWriter("", "")
+//	if err != nil {
+//		// handle err
+//	}
+//	logger := log.NewLeveledLoggerWithFormatter(w, log.Lshortfile, &log.SyslogFormatter{Facility: log.FacilityDaemon})
+//
+// Messages are framed by octet counting of RFC 6587 over TCP, and terminated by newlines over Unix stream sockets
+// as local daemons expect.
+// The connection is reestablished if a write fails, e.g. after the daemon restarts.
+//
+// It's safe for concurrent use.
+type SyslogWriter struct {
+	network string
+	addr    string
+
+	mu      sync.Mutex
+	conn    net.Conn
+	framing syslogFraming
+}
+
+// syslogFraming is how messages are delimited on stream connections.
+type syslogFraming int
+
+const (
+	framingNone       syslogFraming = iota // datagrams
+	framingOctetCount                      // RFC 6587 octet counting
+	framingNewline                         // non-transparent framing with a trailing newline
+)
+
+func syslogFramingOf(network string) syslogFraming {
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+		return framingOctetCount
+	case "unix":
+		return framingNewline
+	}
+	return framingNone
+}
+
+// NewSyslogWriter connects to the syslog daemon at addr of network, e.g. "unixgram", "unix", "udp" or "tcp".
+// If network is empty, the local syslog daemon is connected through Unix sockets, e.g. /dev/log.
+func NewSyslogWriter(network, addr string) (*SyslogWriter, error) {
+	w := &SyslogWriter{network: network, addr: addr}
+	if err := w.connect(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write writes p as a syslog message, it reconnects and retries once on failure.
+func (w *SyslogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn != nil {
+		if err := w.write(p); err == nil {
+			return len(p), nil
+		}
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+	if err := w.connect(); err != nil {
+		return 0, err
+	}
+	if err := w.write(p); err != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the connection, it's reestablished on the next Write.
+func (w *SyslogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+func (w *SyslogWriter) write(p []byte) error {
+	switch w.framing {
+	case framingOctetCount:
+		frame := make([]byte, 0, len(p)+8)
+		frame = strconv.AppendInt(frame, int64(len(p)), 10)
+		frame = append(frame, ' ')
+		p = append(frame, p...)
+	case framingNewline:
+		if len(p) == 0 || p[len(p)-1] != '\n' {
+			p = append(p[:len(p):len(p)], '\n')
+		}
+	}
+	_, err := w.conn.Write(p)
+	return err
+}
+
+func (w *SyslogWriter) connect() error {
+	if w.network != "" {
+		conn, err := net.DialTimeout(w.network, w.addr, dialTimeout)
+		if err != nil {
+			return err
+		}
+		w.conn = conn
+		w.framing = syslogFramingOf(w.network)
+		return nil
+	}
+	for _, path := range localSyslogPaths {
+		for _, network := range []string{"unixgram", "unix"} {
+			if conn, err := net.DialTimeout(network, path, dialTimeout); err == nil {
+				w.conn = conn
+				w.framing = syslogFramingOf(network)
+				return nil
+			}
+		}
+	}
+	return errors.New("log: local syslog daemon is not found")
+}
+
+func isStream(network string) bool {
+	switch network {
+	case "tcp", "tcp4", "tcp6", "unix":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package log
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogFormatter(t *testing.T) {
+	e := &Entry{
+		Time:    time.Date(2009, 1, 23, 1, 23, 23, 123456789, time.FixedZone("", 8*3600)),
+		Level:   WARN,
+		Caller:  runtime.Frame{File: "/a/b/c/d.go", Line: 23},
+		Message: "a message",
+		Fields:  []Field{{Key: "key", Value: "value"}},
+		Name:    "app",
+	}
+	f := &SyslogFormatter{Flag: Lshortfile, AppName: "my app"}
+	exp := "<12>1 2009-01-23T01:23:23.123456+08:00 - my_app " + strconv.Itoa(pid) + " - - d.go:23: [app] a message key=value"
+	if got := string(f.Format(nil, e)); got != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, got)
+	}
+
+	f = &SyslogFormatter{Flag: LUTC, RFC: RFC3164, Facility: FacilityLocal0, Hostname: "host", AppName: "app"}
+	e.Level, e.Name, e.Fields = FATA, "", nil
+	exp = "<130>Jan 22 17:23:23 host app[" + strconv.Itoa(pid) + "]: a message"
+	if got := string(f.Format(nil, e)); got != exp {
+		t.Errorf("Expected '%s', got '%s'", exp, got)
+	}
+}
+
+func TestSyslogWriterTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ln.Close() }()
+
+	w, err := NewSyslogWriter("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = w.Close() }()
+	l := NewLeveledLoggerWithFormatter(w, 0, &SyslogFormatter{RFC: RFC3164, AppName: "app"})
+	l.Info("first line\nsecond line")
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	r := bufio.NewReader(conn)
+	size, err := r.ReadString(' ')
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, _ := strconv.Atoi(strings.TrimSuffix(size, " "))
+	msg := make([]byte, n)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(msg), "<14>") || !strings.HasSuffix(string(msg), "]: first line\nsecond line") {
+		t.Errorf("Unexpected message '%s'", msg)
+	}
+}
+
+func listenUnixgram(t *testing.T, path string) *net.UnixConn {
+	t.Helper()
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+func readDatagram(t *testing.T, conn *net.UnixConn) string {
+	t.Helper()
+	buf := make([]byte, 1024)
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(buf[:n])
+}
+
+func TestSyslogWriterReconnect(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unixgram is not supported on Windows")
+	}
+	// Paths of Unix sockets are limited to about 100 bytes, which t.TempDir may exceed.
+	dir, err := os.MkdirTemp("", "syslog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	path := filepath.Join(dir, "log")
+
+	conn := listenUnixgram(t, path)
+	w, err := NewSyslogWriter("unixgram", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = w.Close() }()
+	l := NewLeveledLoggerWithFormatter(w, 0, &SyslogFormatter{AppName: "app"})
+
+	l.Error("before restart")
+	if msg := readDatagram(t, conn); !strings.HasPrefix(msg, "<11>1 ") || !strings.HasSuffix(msg, " before restart") {
+		t.Errorf("Unexpected message '%s'", msg)
+	}
+
+	// The daemon restarts with a new socket at the same path.
+	_ = conn.Close()
+	_ = os.Remove(path)
+	conn = listenUnixgram(t, path)
+	defer func() { _ = conn.Close() }()
+
+	l.Error("after restart")
+	if msg := readDatagram(t, conn); !strings.HasSuffix(msg, " after restart") {
+		t.Errorf("Unexpected message '%s'", msg)
+	}
+}
+
+func TestSyslogWriterUnixStream(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix sockets are not used for syslog on Windows")
+	}
+	dir, err := os.MkdirTemp("", "syslog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	path := filepath.Join(dir, "log")
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ln.Close() }()
+	w, err := NewSyslogWriter("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = w.Close() }()
+	l := NewLeveledLoggerWithFormatter(w, 0, &SyslogFormatter{RFC: RFC3164, AppName: "app"})
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	l.Info("first")
+	l.Info("second")
+
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	r := bufio.NewReader(conn)
+	for _, exp := range []string{"first", "second"} {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(line, "<14>") || !strings.HasSuffix(line, ": "+exp+"\n") {
+			t.Errorf("Unexpected message '%s'", line)
+		}
+	}
+}